@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"strings"
 )
 
 // Error represents a error from the bitbucket api.
@@ -90,6 +91,17 @@ func (c *Client) Do(method, endpoint string, payload *bytes.Buffer) (*http.Respo
 	return resp, err
 }
 
+// isNotFound tells if the error is bitbucket saying what we asked for does not exist
+func isNotFound(err error) bool {
+	apiError, ok := err.(Error)
+	return ok && apiError.StatusCode == 404
+}
+
+// nextPageEndpoint turns the absolute next link of a paginated response into an endpoint Do can call
+func nextPageEndpoint(next string) string {
+	return strings.TrimPrefix(next, BitbucketEndpoint)
+}
+
 // Get is just a helper method to do but with a GET verb
 func (c *Client) Get(endpoint string) (*http.Response, error) {
 	return c.Do("GET", endpoint, nil)
@@ -0,0 +1,26 @@
+package bitbucket
+
+import "testing"
+
+func TestNextPageEndpoint(t *testing.T) {
+	next := "https://api.bitbucket.org/2.0/repositories/myteam/terraform-code/branch-restrictions?page=2"
+	expected := "2.0/repositories/myteam/terraform-code/branch-restrictions?page=2"
+
+	if endpoint := nextPageEndpoint(next); endpoint != expected {
+		t.Errorf("expected %s, got %s", expected, endpoint)
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	if !isNotFound(Error{StatusCode: 404}) {
+		t.Error("expected a 404 to be not found")
+	}
+
+	if isNotFound(Error{StatusCode: 403}) {
+		t.Error("expected a 403 not to be not found")
+	}
+
+	if isNotFound(nil) {
+		t.Error("expected no error not to be not found")
+	}
+}
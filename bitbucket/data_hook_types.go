@@ -0,0 +1,138 @@
+package bitbucket
+
+//go:generate ../scripts/generate-hook-events.sh
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/didyoumean"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+// HookType is an event a hook can subscribe to
+type HookType struct {
+	Event       string `json:"event"`
+	Category    string `json:"category"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+}
+
+// PaginatedHookTypes is a paginated list that the bitbucket api returns
+type PaginatedHookTypes struct {
+	Values []HookType `json:"values,omitempty"`
+	Page   int        `json:"page,omitempty"`
+	Size   int        `json:"size,omitempty"`
+	Next   string     `json:"next,omitempty"`
+}
+
+func dataHookTypes() *schema.Resource {
+	return &schema.Resource{
+		Read: dataReadHookTypes,
+
+		Schema: map[string]*schema.Schema{
+			"subject_type": {
+				Type:     schema.TypeString,
+				Required: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					"repository",
+					"workspace",
+					"user",
+					"team",
+				},
+					false),
+			},
+			"hook_types": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"event": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"category": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"label": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataReadHookTypes(d *schema.ResourceData, m interface{}) error {
+	c := m.(*Client)
+
+	subjectType := d.Get("subject_type").(string)
+	resourceURL := fmt.Sprintf("2.0/hook_events/%s", subjectType)
+
+	var hookTypes []interface{}
+
+	for {
+		r, err := c.Get(resourceURL)
+		if err != nil {
+			return err
+		}
+
+		var page PaginatedHookTypes
+		err = json.NewDecoder(r.Body).Decode(&page)
+		r.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		for _, hookType := range page.Values {
+			hookTypes = append(hookTypes, map[string]interface{}{
+				"event":       hookType.Event,
+				"category":    hookType.Category,
+				"label":       hookType.Label,
+				"description": hookType.Description,
+			})
+		}
+
+		if page.Next == "" {
+			break
+		}
+
+		resourceURL = nextPageEndpoint(page.Next)
+	}
+
+	d.SetId(subjectType)
+	d.Set("hook_types", hookTypes)
+
+	return nil
+}
+
+// validateHookEvent checks events against the built in catalog, suggesting the closest event for typos. The
+// catalog is refreshed with `go generate`.
+func validateHookEvent(subjectType string) schema.SchemaValidateFunc {
+	return func(v interface{}, k string) (ws []string, errors []error) {
+		event := v.(string)
+		events := hookEventsCatalog[subjectType]
+
+		for _, known := range events {
+			if event == known {
+				return
+			}
+		}
+
+		if suggestion := didyoumean.NameSuggestion(event, events); suggestion != "" {
+			errors = append(errors, fmt.Errorf("%q is not a valid %s hook event, did you mean %q?", event, subjectType, suggestion))
+			return
+		}
+
+		errors = append(errors, fmt.Errorf("%q is not a valid %s hook event, the bitbucket_hook_types data source lists the valid events", event, subjectType))
+		return
+	}
+}
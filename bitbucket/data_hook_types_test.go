@@ -0,0 +1,51 @@
+package bitbucket
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccBitbucketHookTypes_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "bitbucket_hook_types" "repository" {
+						subject_type = "repository"
+					}
+				`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.bitbucket_hook_types.repository", "hook_types.0.event"),
+				),
+			},
+		},
+	})
+}
+
+func TestValidateHookEvent(t *testing.T) {
+	validate := validateHookEvent("repository")
+
+	if _, errs := validate("repo:push", "events"); len(errs) != 0 {
+		t.Errorf("expected repo:push to be valid, got %v", errs)
+	}
+
+	_, errs := validate("repo:pushed", "events")
+	if len(errs) != 1 {
+		t.Fatalf("expected repo:pushed to be invalid")
+	}
+	if !strings.Contains(errs[0].Error(), `did you mean "repo:push"?`) {
+		t.Errorf("expected a suggestion for repo:pushed, got %s", errs[0])
+	}
+
+	_, errs = validate("something:else", "events")
+	if len(errs) != 1 {
+		t.Fatalf("expected something:else to be invalid")
+	}
+	if strings.Contains(errs[0].Error(), "did you mean") {
+		t.Errorf("expected no suggestion for something:else, got %s", errs[0])
+	}
+}
@@ -0,0 +1,172 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// OpenIDConfiguration is the part of the OpenID Connect discovery document we use
+type OpenIDConfiguration struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+// JSONWebKeySet are the keys pipelines sign their OIDC tokens with
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JSONWebKey is a single RSA signing key
+type JSONWebKey struct {
+	KeyID     string `json:"kid"`
+	KeyType   string `json:"kty"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+}
+
+// apiWorkspace is the part of a workspace the OIDC audience is made of
+type apiWorkspace struct {
+	UUID string `json:"uuid"`
+}
+
+func dataPipelineOIDCConfig() *schema.Resource {
+	return &schema.Resource{
+		Read: dataReadPipelineOIDCConfig,
+
+		Schema: map[string]*schema.Schema{
+			"workspace": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"issuer": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"audience": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"jwks_uri": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"jwks": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"keys": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"kid": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"kty": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"alg": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"use": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"n": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"e": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// pipelineOIDCAudience is the audience of the OIDC tokens of a workspace's pipelines, made of the workspace UUID
+// without its braces
+func pipelineOIDCAudience(workspaceUUID string) string {
+	return fmt.Sprintf("ari:cloud:bitbucket::workspace/%s", strings.Trim(workspaceUUID, "{}"))
+}
+
+func dataReadPipelineOIDCConfig(d *schema.ResourceData, m interface{}) error {
+	c := m.(*Client)
+
+	workspace := d.Get("workspace").(string)
+	oidcURL := fmt.Sprintf("2.0/workspaces/%s/pipelines-config/identity/oidc", workspace)
+
+	r, err := c.Get(fmt.Sprintf("2.0/workspaces/%s", workspace))
+	if err != nil {
+		return err
+	}
+
+	var ws apiWorkspace
+	err = json.NewDecoder(r.Body).Decode(&ws)
+	r.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	r, err = c.Get(oidcURL + "/.well-known/openid-configuration")
+	if err != nil {
+		return err
+	}
+
+	var config OpenIDConfiguration
+	err = json.NewDecoder(r.Body).Decode(&config)
+	r.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	r, err = c.Get(oidcURL + "/keys.json")
+	if err != nil {
+		return err
+	}
+
+	// The key set is also exposed as is, for cloud providers that take the JSON document.
+	jwks, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	var keySet JSONWebKeySet
+	if err := json.Unmarshal(jwks, &keySet); err != nil {
+		return err
+	}
+
+	var keys []interface{}
+	for _, key := range keySet.Keys {
+		keys = append(keys, map[string]interface{}{
+			"kid": key.KeyID,
+			"kty": key.KeyType,
+			"alg": key.Algorithm,
+			"use": key.Use,
+			"n":   key.Modulus,
+			"e":   key.Exponent,
+		})
+	}
+
+	d.SetId(workspace)
+	d.Set("issuer", config.Issuer)
+	d.Set("audience", pipelineOIDCAudience(ws.UUID))
+	d.Set("jwks_uri", config.JWKSURI)
+	d.Set("jwks", string(jwks))
+	d.Set("keys", keys)
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package bitbucket
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccBitbucketPipelineOIDCConfig_basic(t *testing.T) {
+	testUser := os.Getenv("BITBUCKET_USERNAME")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					data "bitbucket_pipeline_oidc_config" "test" {
+						workspace = "%s"
+					}
+				`, testUser),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.bitbucket_pipeline_oidc_config.test", "issuer",
+						fmt.Sprintf("https://api.bitbucket.org/2.0/workspaces/%s/pipelines-config/identity/oidc", testUser)),
+					resource.TestMatchResourceAttr("data.bitbucket_pipeline_oidc_config.test", "audience",
+						regexp.MustCompile(`^ari:cloud:bitbucket::workspace/[0-9a-f-]+$`)),
+					resource.TestCheckResourceAttrSet("data.bitbucket_pipeline_oidc_config.test", "keys.0.kid"),
+					resource.TestCheckResourceAttrSet("data.bitbucket_pipeline_oidc_config.test", "jwks"),
+				),
+			},
+		},
+	})
+}
+
+func TestPipelineOIDCAudience(t *testing.T) {
+	expected := "ari:cloud:bitbucket::workspace/c0ffee00-0000-0000-0000-000000000000"
+	if actual := pipelineOIDCAudience("{c0ffee00-0000-0000-0000-000000000000}"); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+}
@@ -0,0 +1,63 @@
+// Code generated by scripts/generate-hook-events.sh; DO NOT EDIT.
+
+package bitbucket
+
+// hookEventsCatalog is every event a hook can subscribe to, keyed by the subject type of the hook
+var hookEventsCatalog = map[string][]string{
+	"repository": {
+		"issue:comment_created",
+		"issue:created",
+		"issue:updated",
+		"pullrequest:approved",
+		"pullrequest:changes_request_created",
+		"pullrequest:changes_request_removed",
+		"pullrequest:comment_created",
+		"pullrequest:comment_deleted",
+		"pullrequest:comment_reopened",
+		"pullrequest:comment_resolved",
+		"pullrequest:comment_updated",
+		"pullrequest:created",
+		"pullrequest:fulfilled",
+		"pullrequest:push",
+		"pullrequest:rejected",
+		"pullrequest:unapproved",
+		"pullrequest:updated",
+		"repo:commit_comment_created",
+		"repo:commit_status_created",
+		"repo:commit_status_updated",
+		"repo:fork",
+		"repo:imported",
+		"repo:push",
+		"repo:transfer",
+		"repo:updated",
+	},
+	"workspace": {
+		"issue:comment_created",
+		"issue:created",
+		"issue:updated",
+		"project:updated",
+		"pullrequest:approved",
+		"pullrequest:changes_request_created",
+		"pullrequest:changes_request_removed",
+		"pullrequest:comment_created",
+		"pullrequest:comment_deleted",
+		"pullrequest:comment_reopened",
+		"pullrequest:comment_resolved",
+		"pullrequest:comment_updated",
+		"pullrequest:created",
+		"pullrequest:fulfilled",
+		"pullrequest:push",
+		"pullrequest:rejected",
+		"pullrequest:unapproved",
+		"pullrequest:updated",
+		"repo:commit_comment_created",
+		"repo:commit_status_created",
+		"repo:commit_status_updated",
+		"repo:created",
+		"repo:fork",
+		"repo:imported",
+		"repo:push",
+		"repo:transfer",
+		"repo:updated",
+	},
+}
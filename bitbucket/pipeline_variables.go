@@ -0,0 +1,351 @@
+package bitbucket
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// PipelineVariable is a pipelines variable of a workspace, repository or deployment environment
+type PipelineVariable struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	UUID    string `json:"uuid,omitempty"`
+	Secured bool   `json:"secured"`
+}
+
+// PaginatedPipelineVariables is a paginated list that the bitbucket api returns
+type PaginatedPipelineVariables struct {
+	Values []PipelineVariable `json:"values,omitempty"`
+	Page   int                `json:"page,omitempty"`
+	Size   int                `json:"size,omitempty"`
+	Next   string             `json:"next,omitempty"`
+}
+
+var variableKeyRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// reservedVariableKeyPrefixes are used by the variables bitbucket sets in every build
+var reservedVariableKeyPrefixes = []string{"BITBUCKET_", "PIPELINES_", "PIPELINE_"}
+
+// validateVariableKey checks bitbucket's naming rules for pipelines variables: ASCII letters, digits and
+// underscores, not starting with a digit, and not taking the names of the variables bitbucket provides.
+func validateVariableKey(v interface{}, k string) (ws []string, errors []error) {
+	key := v.(string)
+
+	if !variableKeyRegexp.MatchString(key) {
+		errors = append(errors, fmt.Errorf("%s %q may only contain ASCII letters, digits and underscores and can not start with a digit", k, key))
+		return
+	}
+
+	for _, prefix := range reservedVariableKeyPrefixes {
+		if strings.HasPrefix(strings.ToUpper(key), prefix) {
+			errors = append(errors, fmt.Errorf("%s %q can not start with %s, it is reserved for variables set by bitbucket", k, key, prefix))
+			return
+		}
+	}
+
+	return
+}
+
+// resourceVariableKeyDiff checks the key of new variables and of ones whose key changes. Variables with keys from
+// before they were checked keep working until the key is changed.
+func resourceVariableKeyDiff(d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() != "" && !d.HasChange("key") {
+		return nil
+	}
+
+	if !d.NewValueKnown("key") {
+		return nil
+	}
+
+	if _, errors := validateVariableKey(d.Get("key"), "key"); len(errors) > 0 {
+		return errors[0]
+	}
+
+	return nil
+}
+
+// pipelineVariablesURL is the collection of variables of a scope. The scope is a workspace, a repository as
+// `owner/slug` or a deployment environment as `owner/slug:{uuid}`, the ID of a bitbucket_deployment.
+func pipelineVariablesURL(scope string) string {
+	if strings.Contains(scope, ":") {
+		repository, deployment := parseDeploymentId(scope)
+		return fmt.Sprintf("2.0/repositories/%s/deployments_config/environments/%s/variables", repository, deployment)
+	}
+
+	if strings.Contains(scope, "/") {
+		return fmt.Sprintf("2.0/repositories/%s/pipelines_config/variables", scope)
+	}
+
+	return fmt.Sprintf("2.0/workspaces/%s/pipelines-config/variables", scope)
+}
+
+// listPipelineVariables fetches every page of variables of the scope
+func listPipelineVariables(client *Client, scope string) ([]PipelineVariable, error) {
+	var variables []PipelineVariable
+
+	resourceURL := pipelineVariablesURL(scope)
+
+	for {
+		req, err := client.Get(resourceURL)
+		if err != nil {
+			return nil, err
+		}
+
+		var page PaginatedPipelineVariables
+		decoder := json.NewDecoder(req.Body)
+		err = decoder.Decode(&page)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		variables = append(variables, page.Values...)
+
+		if page.Next == "" {
+			break
+		}
+
+		resourceURL = nextPageEndpoint(page.Next)
+	}
+
+	return variables, nil
+}
+
+// findPipelineVariableByKey looks a variable up by its key, keys are unique within a scope. It returns nil when
+// either of them is gone.
+func findPipelineVariableByKey(client *Client, scope, key string) (*PipelineVariable, error) {
+	variables, err := listPipelineVariables(client, scope)
+	if isNotFound(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, variable := range variables {
+		if variable.Key == key {
+			return &variable, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// findPipelineVariableByUUID looks a variable up in the listing of the scope, for scopes whose variables can not be
+// fetched one by one. It returns nil when either of them is gone.
+func findPipelineVariableByUUID(client *Client, scope, uuid string) (*PipelineVariable, error) {
+	variables, err := listPipelineVariables(client, scope)
+	if isNotFound(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, variable := range variables {
+		if variable.UUID == uuid {
+			return &variable, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// readPipelineVariable finds the variable of the resource by its UUID with lookup, or by its key when the UUID is
+// not known or gone. It returns nil when there is no variable by either.
+func readPipelineVariable(d *schema.ResourceData, client *Client, scope string,
+	lookup func(client *Client, scope, uuid string) (*PipelineVariable, error)) (*PipelineVariable, error) {
+
+	var rv *PipelineVariable
+	var err error
+
+	if uuid := d.Get("uuid").(string); uuid != "" {
+		rv, err = lookup(client, scope, uuid)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if rv != nil {
+		return rv, nil
+	}
+
+	// The UUID is not known after an import, and changes when the variable was recreated outside of terraform.
+	rv, err = findPipelineVariableByKey(client, scope, d.Get("key").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	if rv != nil && d.Get("uuid").(string) != "" {
+		forgetSecuredVariableValue(d, rv.Secured)
+	}
+
+	return rv, nil
+}
+
+// getPipelineVariable fetches a single variable of the scope, it returns nil when the variable is gone
+func getPipelineVariable(client *Client, scope, uuid string) (*PipelineVariable, error) {
+	req, err := client.Get(fmt.Sprintf("%s/%s", pipelineVariablesURL(scope), url.PathEscape(uuid)))
+
+	if req != nil && req.StatusCode == 404 {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	body, readerr := ioutil.ReadAll(req.Body)
+	if readerr != nil {
+		return nil, readerr
+	}
+
+	var variable PipelineVariable
+	decodeerr := json.Unmarshal(body, &variable)
+	if decodeerr != nil {
+		return nil, decodeerr
+	}
+
+	return &variable, nil
+}
+
+func postPipelineVariable(client *Client, scope string, variable *PipelineVariable) (*PipelineVariable, error) {
+	bytedata, err := json.Marshal(variable)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := client.Post(pipelineVariablesURL(scope), bytes.NewBuffer(bytedata))
+	if err != nil {
+		return nil, err
+	}
+
+	body, readerr := ioutil.ReadAll(req.Body)
+	if readerr != nil {
+		return nil, readerr
+	}
+
+	var created PipelineVariable
+	decodeerr := json.Unmarshal(body, &created)
+	if decodeerr != nil {
+		return nil, decodeerr
+	}
+
+	return &created, nil
+}
+
+func putPipelineVariable(client *Client, scope, uuid string, variable *PipelineVariable) error {
+	bytedata, err := json.Marshal(variable)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Put(fmt.Sprintf("%s/%s", pipelineVariablesURL(scope), url.PathEscape(uuid)), bytes.NewBuffer(bytedata))
+	return err
+}
+
+// deletePipelineVariable removes the variable, one that is already gone is not an error
+func deletePipelineVariable(client *Client, scope, uuid string) error {
+	req, err := client.Delete(fmt.Sprintf("%s/%s", pipelineVariablesURL(scope), url.PathEscape(uuid)))
+
+	if req != nil && req.StatusCode == 404 {
+		return nil
+	}
+
+	return err
+}
+
+// hashVariableValue is what we keep in value_hash, so changes to a value show up without the value itself. It is
+// keyed with a random salt per variable, so the hash of a short or common value can not be looked up.
+func hashVariableValue(salt, value string) string {
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// variableValueHashSalt returns the salt of the value hash, variables that do not have one yet get a new one
+func variableValueHashSalt(d *schema.ResourceData) (string, error) {
+	if salt := d.Get("value_hash_salt").(string); salt != "" {
+		return salt, nil
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	salt := hex.EncodeToString(buf)
+	d.Set("value_hash_salt", salt)
+
+	return salt, nil
+}
+
+// setVariableValueHash stores the hash of the value we wrote
+func setVariableValueHash(d *schema.ResourceData, value string) error {
+	salt, err := variableValueHashSalt(d)
+	if err != nil {
+		return err
+	}
+
+	d.Set("value_hash", hashVariableValue(salt, value))
+
+	return nil
+}
+
+// setVariableValue stores what bitbucket returned for a variable. Bitbucket never returns the value of a secured
+// variable, for those the value we wrote is kept so it does not show up as a change on every plan. That also means
+// a secured value changed outside of terraform goes unnoticed, only a variable that was recreated can be caught.
+func setVariableValue(d *schema.ResourceData, secured bool, value string) error {
+	d.Set("secured", secured)
+
+	if secured {
+		return nil
+	}
+
+	d.Set("value", value)
+
+	return setVariableValueHash(d, value)
+}
+
+// forgetSecuredVariableValue is for secured variables that were recreated outside of terraform, with a value we
+// know nothing about. Clearing the hash makes the next plan write the configured value again.
+func forgetSecuredVariableValue(d *schema.ResourceData, secured bool) {
+	if secured {
+		d.Set("value_hash", "")
+	}
+}
+
+func resourceVariableValueHashDiff(d *schema.ResourceDiff, m interface{}) error {
+	salt := d.Get("value_hash_salt").(string)
+
+	// New variables, and ones from before the hash was salted, get their salt when they are written.
+	if salt == "" {
+		if err := d.SetNewComputed("value_hash_salt"); err != nil {
+			return err
+		}
+		return d.SetNewComputed("value_hash")
+	}
+
+	if !d.NewValueKnown("value") {
+		return d.SetNewComputed("value_hash")
+	}
+
+	if hash := hashVariableValue(salt, d.Get("value").(string)); d.Get("value_hash").(string) != hash {
+		return d.SetNew("value_hash", hash)
+	}
+
+	return nil
+}
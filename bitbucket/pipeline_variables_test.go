@@ -0,0 +1,87 @@
+package bitbucket
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestSetVariableValue(t *testing.T) {
+	raw := map[string]interface{}{
+		"key":        "TOKEN",
+		"value":      "hunter2",
+		"secured":    true,
+		"repository": "gob/illusions",
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceRepositoryVariable().Schema, raw)
+	if err := setVariableValueHash(d, "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+
+	salt := d.Get("value_hash_salt").(string)
+	if salt == "" {
+		t.Fatal("expected a salt to be made for the value hash")
+	}
+
+	// Bitbucket leaves the value of secured variables out.
+	if err := setVariableValue(d, true, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := d.Get("value").(string); v != "hunter2" {
+		t.Errorf("expected the configured value to be kept, got %q", v)
+	}
+
+	if v := d.Get("value_hash").(string); v != hashVariableValue(salt, "hunter2") {
+		t.Errorf("expected the value hash to be kept, got %q", v)
+	}
+
+	if err := setVariableValue(d, false, "changed"); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := d.Get("value").(string); v != "changed" {
+		t.Errorf("expected the value from bitbucket, got %q", v)
+	}
+
+	if v := d.Get("value_hash_salt").(string); v != salt {
+		t.Errorf("expected the salt to be kept, got %q", v)
+	}
+
+	if v := d.Get("value_hash").(string); v != hashVariableValue(salt, "changed") {
+		t.Errorf("expected the value hash to follow the value, got %q", v)
+	}
+
+	if d.Get("secured").(bool) {
+		t.Errorf("expected secured to be read back")
+	}
+}
+
+func TestHashVariableValue(t *testing.T) {
+	if hashVariableValue("salt", "hunter2") != hashVariableValue("salt", "hunter2") {
+		t.Error("expected the same value and salt to hash the same")
+	}
+
+	if hashVariableValue("salt", "hunter2") == hashVariableValue("pepper", "hunter2") {
+		t.Error("expected the same value with another salt to hash differently")
+	}
+
+	if hashVariableValue("salt", "hunter2") == hashVariableValue("salt", "hunter3") {
+		t.Error("expected different values to hash differently")
+	}
+}
+
+func TestValidateVariableKey(t *testing.T) {
+	for _, key := range []string{"DEBUG", "_private", "registry_token2"} {
+		if _, errors := validateVariableKey(key, "key"); len(errors) > 0 {
+			t.Errorf("expected %s to be valid, got %v", key, errors)
+		}
+	}
+
+	for _, key := range []string{"", "2FA", "MY-VAR", "BITBUCKET_COMMIT", "pipelines_token", "PIPELINE_UUID"} {
+		if _, errors := validateVariableKey(key, "key"); len(errors) == 0 {
+			t.Errorf("expected %s to be invalid", key)
+		}
+	}
+}
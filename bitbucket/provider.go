@@ -25,17 +25,34 @@ func Provider() terraform.ResourceProvider {
 		},
 		ConfigureFunc: providerConfigure,
 		ResourcesMap: map[string]*schema.Resource{
-			"bitbucket_hook":                resourceHook(),
-			"bitbucket_default_reviewers":   resourceDefaultReviewers(),
-			"bitbucket_repository":          resourceRepository(),
-			"bitbucket_repository_variable": resourceRepositoryVariable(),
-			"bitbucket_project":             resourceProject(),
-			"bitbucket_branch_restriction":  resourceBranchRestriction(),
-			"bitbucket_deployment":          resourceDeployment(),
-			"bitbucket_deployment_variable": resourceDeploymentVariable(),
+			"bitbucket_hook":                      resourceHook(),
+			"bitbucket_workspace_hook":            resourceWorkspaceHook(),
+			"bitbucket_repository_hooks":          resourceRepositoryHooks(),
+			"bitbucket_default_reviewers":         resourceDefaultReviewers(),
+			"bitbucket_repository":                resourceRepository(),
+			"bitbucket_repository_variable":       resourceRepositoryVariable(),
+			"bitbucket_deploy_key":                resourceDeployKey(),
+			"bitbucket_project":                   resourceProject(),
+			"bitbucket_project_deploy_key":        resourceProjectDeployKey(),
+			"bitbucket_branch_restriction":        resourceBranchRestriction(),
+			"bitbucket_branch_protection":         resourceBranchProtection(),
+			"bitbucket_branch_restrictions":       resourceRepositoryBranchRestrictions(),
+			"bitbucket_branch_restriction_policy": resourceBranchRestrictionPolicy(),
+			"bitbucket_deployment":                resourceDeployment(),
+			"bitbucket_deployment_variable":       resourceDeploymentVariable(),
+			"bitbucket_pipeline_variables":        resourcePipelineVariables(),
+			"bitbucket_pipeline_config":           resourcePipelineConfig(),
+			"bitbucket_pipeline_ssh_key":          resourcePipelineSSHKey(),
+			"bitbucket_pipeline_known_host":       resourcePipelineKnownHost(),
+			"bitbucket_pipeline_schedule":         resourcePipelineSchedule(),
+			"bitbucket_pipeline_run":              resourcePipelineRun(),
+			"bitbucket_pipeline_runner":           resourcePipelineRunner(),
+			"bitbucket_workspace_variable":        resourceWorkspaceVariable(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"bitbucket_user": dataUser(),
+			"bitbucket_user":                 dataUser(),
+			"bitbucket_hook_types":           dataHookTypes(),
+			"bitbucket_pipeline_oidc_config": dataPipelineOIDCConfig(),
 		},
 	}
 }
@@ -0,0 +1,448 @@
+package bitbucket
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+// branchProtectionValueKinds maps the numeric arguments of a branch protection to the kind of branch
+// restriction that holds the number
+var branchProtectionValueKinds = map[string]string{
+	"required_approvals":                  "require_approvals_to_merge",
+	"required_default_reviewer_approvals": "require_default_reviewer_approvals_to_merge",
+	"required_passing_builds":             "require_passing_builds_to_merge",
+}
+
+// branchProtectionKinds is every kind of branch restriction a branch protection manages
+var branchProtectionKinds = []string{
+	"require_approvals_to_merge",
+	"require_default_reviewer_approvals_to_merge",
+	"require_passing_builds_to_merge",
+	"require_tasks_to_be_completed",
+	"force",
+	"delete",
+	"push",
+	"restrict_merges",
+}
+
+func resourceBranchProtection() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceBranchProtectionCreate,
+		Read:   resourceBranchProtectionRead,
+		Update: resourceBranchProtectionUpdate,
+		Delete: resourceBranchProtectionDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceBranchProtectionImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultVisibilityTimeout),
+			Update: schema.DefaultTimeout(defaultVisibilityTimeout),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"owner": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"repository": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"pattern": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"required_approvals": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"required_default_reviewer_approvals": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"required_passing_builds": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"require_tasks_completed": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"allow_force_push": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"allow_delete": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"push_users": {
+				Type:     schema.TypeSet,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Optional: true,
+				Set:      schema.HashString,
+			},
+			"push_groups": {
+				Type:     schema.TypeSet,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Optional: true,
+				Set:      schema.HashString,
+			},
+			"merge_users": {
+				Type:     schema.TypeSet,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Optional: true,
+				Set:      schema.HashString,
+			},
+			"merge_groups": {
+				Type:     schema.TypeSet,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Optional: true,
+				Set:      schema.HashString,
+			},
+			"restriction_ids": {
+				Type:     schema.TypeMap,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Computed: true,
+			},
+		},
+	}
+}
+
+// newBranchRestrictionWithMembers builds a branch restriction that lists users and group slugs of the owner
+func newBranchRestrictionWithMembers(kind, owner, pattern string, users, groups *schema.Set) *BranchRestriction {
+	branchRestriction := &BranchRestriction{
+		Kind:    kind,
+		Pattern: pattern,
+		Users:   make([]User, 0, users.Len()),
+		Groups:  make([]Group, 0, groups.Len()),
+	}
+
+	for _, item := range users.List() {
+		branchRestriction.Users = append(branchRestriction.Users, User{Username: item.(string)})
+	}
+
+	for _, item := range groups.List() {
+		branchRestriction.Groups = append(branchRestriction.Groups, Group{Owner: User{Username: owner}, Slug: item.(string)})
+	}
+
+	return branchRestriction
+}
+
+// newBranchProtectionRestrictions returns the branch restrictions the configuration stands for keyed by kind
+func newBranchProtectionRestrictions(d *schema.ResourceData) map[string]*BranchRestriction {
+	owner := d.Get("owner").(string)
+	pattern := d.Get("pattern").(string)
+	restrictions := make(map[string]*BranchRestriction)
+
+	for field, kind := range branchProtectionValueKinds {
+		if v := d.Get(field).(int); v > 0 {
+			restrictions[kind] = &BranchRestriction{Kind: kind, Pattern: pattern, Value: v}
+		}
+	}
+
+	if d.Get("require_tasks_completed").(bool) {
+		restrictions["require_tasks_to_be_completed"] = &BranchRestriction{Kind: "require_tasks_to_be_completed", Pattern: pattern}
+	}
+
+	if !d.Get("allow_force_push").(bool) {
+		restrictions["force"] = &BranchRestriction{Kind: "force", Pattern: pattern}
+	}
+
+	if !d.Get("allow_delete").(bool) {
+		restrictions["delete"] = &BranchRestriction{Kind: "delete", Pattern: pattern}
+	}
+
+	pushUsers := d.Get("push_users").(*schema.Set)
+	pushGroups := d.Get("push_groups").(*schema.Set)
+	if pushUsers.Len() > 0 || pushGroups.Len() > 0 {
+		restrictions["push"] = newBranchRestrictionWithMembers("push", owner, pattern, pushUsers, pushGroups)
+	}
+
+	mergeUsers := d.Get("merge_users").(*schema.Set)
+	mergeGroups := d.Get("merge_groups").(*schema.Set)
+	if mergeUsers.Len() > 0 || mergeGroups.Len() > 0 {
+		restrictions["restrict_merges"] = newBranchRestrictionWithMembers("restrict_merges", owner, pattern, mergeUsers, mergeGroups)
+	}
+
+	return restrictions
+}
+
+func branchRestrictionMembers(branchRestriction *BranchRestriction) ([]string, []string) {
+	users := make([]string, 0, len(branchRestriction.Users))
+	for _, user := range branchRestriction.Users {
+		users = append(users, user.Username)
+	}
+
+	groups := make([]string, 0, len(branchRestriction.Groups))
+	for _, group := range branchRestriction.Groups {
+		groups = append(groups, group.Slug)
+	}
+
+	return users, groups
+}
+
+// branchProtectionRestrictionsOnPattern picks the restrictions a branch protection of the pattern would manage out
+// of the restrictions of a repository, keyed by kind. When a kind is there more than once the first one is used.
+func branchProtectionRestrictionsOnPattern(branchRestrictions []BranchRestriction, pattern string) map[string]BranchRestriction {
+	restrictions := make(map[string]BranchRestriction)
+
+	for _, branchRestriction := range branchRestrictions {
+		if branchRestriction.Pattern != pattern {
+			continue
+		}
+
+		if branchRestriction.BranchMatchKind != "" && branchRestriction.BranchMatchKind != "glob" {
+			continue
+		}
+
+		if _, ok := restrictions[branchRestriction.Kind]; ok {
+			continue
+		}
+
+		for _, kind := range branchProtectionKinds {
+			if branchRestriction.Kind == kind {
+				restrictions[kind] = branchRestriction
+				break
+			}
+		}
+	}
+
+	return restrictions
+}
+
+func existingBranchProtectionRestrictions(client *Client, owner, repository, pattern string) (map[string]BranchRestriction, error) {
+	branchRestrictions, err := listBranchRestrictions(client, owner, repository)
+	if err != nil {
+		return nil, err
+	}
+
+	return branchProtectionRestrictionsOnPattern(branchRestrictions, pattern), nil
+}
+
+// putOrPostBranchProtectionRestriction adopts an existing restriction of the kind on the pattern rather than adding
+// a second one, and returns the id of the restriction that implements the kind
+func putOrPostBranchProtectionRestriction(client *Client, owner, repository string, existing map[string]BranchRestriction, branchRestriction *BranchRestriction, timeout time.Duration) (string, error) {
+	if adopted, ok := existing[branchRestriction.Kind]; ok {
+		id := strconv.Itoa(adopted.ID)
+		return id, putBranchRestriction(client, owner, repository, id, branchRestriction)
+	}
+
+	created, err := postBranchRestriction(client, owner, repository, branchRestriction, timeout)
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.Itoa(created.ID), nil
+}
+
+func resourceBranchProtectionCreate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+	owner := d.Get("owner").(string)
+	repository := d.Get("repository").(string)
+	pattern := d.Get("pattern").(string)
+
+	existing, err := existingBranchProtectionRestrictions(client, owner, repository, pattern)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s/%s", owner, repository, pattern))
+
+	ids := make(map[string]string)
+	for kind, branchRestriction := range newBranchProtectionRestrictions(d) {
+		id, err := putOrPostBranchProtectionRestriction(client, owner, repository, existing, branchRestriction, d.Timeout(schema.TimeoutCreate))
+		if err != nil {
+			// Keep what we did create so a destroy can clean it up.
+			d.Set("restriction_ids", ids)
+			return err
+		}
+		ids[kind] = id
+	}
+
+	d.Set("restriction_ids", ids)
+
+	return resourceBranchProtectionRead(d, m)
+}
+
+func resourceBranchProtectionRead(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+	owner := d.Get("owner").(string)
+	repository := d.Get("repository").(string)
+
+	restrictions := make(map[string]*BranchRestriction)
+	ids := make(map[string]string)
+
+	for kind, id := range d.Get("restriction_ids").(map[string]interface{}) {
+		branchRestriction, err := getBranchRestriction(client, owner, repository, id.(string))
+		if err != nil {
+			return err
+		}
+
+		// Removed in the UI, leaving it out shows up as a diff that recreates it.
+		if branchRestriction == nil {
+			continue
+		}
+
+		restrictions[kind] = branchRestriction
+		ids[kind] = id.(string)
+	}
+
+	d.Set("restriction_ids", ids)
+
+	for field, kind := range branchProtectionValueKinds {
+		if branchRestriction, ok := restrictions[kind]; ok {
+			d.Set(field, branchRestriction.Value)
+		} else {
+			d.Set(field, 0)
+		}
+	}
+
+	_, requireTasks := restrictions["require_tasks_to_be_completed"]
+	d.Set("require_tasks_completed", requireTasks)
+
+	_, force := restrictions["force"]
+	d.Set("allow_force_push", !force)
+
+	_, deleteBranch := restrictions["delete"]
+	d.Set("allow_delete", !deleteBranch)
+
+	pushUsers, pushGroups := []string{}, []string{}
+	if branchRestriction, ok := restrictions["push"]; ok {
+		pushUsers, pushGroups = branchRestrictionMembers(branchRestriction)
+	}
+	d.Set("push_users", pushUsers)
+	d.Set("push_groups", pushGroups)
+
+	mergeUsers, mergeGroups := []string{}, []string{}
+	if branchRestriction, ok := restrictions["restrict_merges"]; ok {
+		mergeUsers, mergeGroups = branchRestrictionMembers(branchRestriction)
+	}
+	d.Set("merge_users", mergeUsers)
+	d.Set("merge_groups", mergeGroups)
+
+	return nil
+}
+
+func resourceBranchProtectionUpdate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+	owner := d.Get("owner").(string)
+	repository := d.Get("repository").(string)
+
+	ids := make(map[string]string)
+	for kind, id := range d.Get("restriction_ids").(map[string]interface{}) {
+		ids[kind] = id.(string)
+	}
+
+	desired := newBranchProtectionRestrictions(d)
+
+	// Kinds that are new to the protection may already be on the pattern.
+	var existing map[string]BranchRestriction
+	for kind := range desired {
+		if _, ok := ids[kind]; ok {
+			continue
+		}
+
+		var err error
+		existing, err = existingBranchProtectionRestrictions(client, owner, repository, d.Get("pattern").(string))
+		if err != nil {
+			return err
+		}
+		break
+	}
+
+	for kind, id := range ids {
+		if _, ok := desired[kind]; ok {
+			continue
+		}
+
+		if err := deleteBranchRestriction(client, owner, repository, id); err != nil {
+			d.Set("restriction_ids", ids)
+			return err
+		}
+		delete(ids, kind)
+	}
+
+	for kind, branchRestriction := range desired {
+		if id, ok := ids[kind]; ok {
+			if err := putBranchRestriction(client, owner, repository, id, branchRestriction); err != nil {
+				d.Set("restriction_ids", ids)
+				return err
+			}
+			continue
+		}
+
+		id, err := putOrPostBranchProtectionRestriction(client, owner, repository, existing, branchRestriction, d.Timeout(schema.TimeoutUpdate))
+		if err != nil {
+			d.Set("restriction_ids", ids)
+			return err
+		}
+		ids[kind] = id
+	}
+
+	d.Set("restriction_ids", ids)
+
+	return resourceBranchProtectionRead(d, m)
+}
+
+func resourceBranchProtectionDelete(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	for _, id := range d.Get("restriction_ids").(map[string]interface{}) {
+		err := deleteBranchRestriction(client,
+			d.Get("owner").(string),
+			d.Get("repository").(string),
+			id.(string),
+		)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resourceBranchProtectionImport takes `owner/repository/pattern` and picks up the restrictions already on the pattern
+func resourceBranchProtectionImport(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	idparts := strings.SplitN(d.Id(), "/", 3)
+	if len(idparts) != 3 || idparts[2] == "" {
+		return nil, fmt.Errorf("Incorrect ID format, should match `owner/repository/pattern`")
+	}
+
+	existing, err := existingBranchProtectionRestrictions(m.(*Client), idparts[0], idparts[1], idparts[2])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(existing) == 0 {
+		return nil, fmt.Errorf("no branch restrictions on %s in %s/%s", idparts[2], idparts[0], idparts[1])
+	}
+
+	ids := make(map[string]string)
+	for kind, branchRestriction := range existing {
+		ids[kind] = strconv.Itoa(branchRestriction.ID)
+	}
+
+	d.Set("owner", idparts[0])
+	d.Set("repository", idparts[1])
+	d.Set("pattern", idparts[2])
+	d.Set("restriction_ids", ids)
+
+	return []*schema.ResourceData{d}, nil
+}
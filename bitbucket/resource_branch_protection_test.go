@@ -0,0 +1,134 @@
+package bitbucket
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccBitbucketBranchProtection_basic(t *testing.T) {
+	testUser := os.Getenv("BITBUCKET_USERNAME")
+	testAccBitbucketBranchProtectionConfig := fmt.Sprintf(`
+		resource "bitbucket_repository" "test_repo" {
+			owner = "%s"
+			name = "test-repo-for-branch-protection-test"
+		}
+		resource "bitbucket_branch_protection" "master" {
+			owner = "%s"
+			repository = "${bitbucket_repository.test_repo.name}"
+			pattern = "master"
+			required_approvals = 2
+			required_passing_builds = 1
+			push_users = ["%s"]
+		}
+	`, testUser, testUser, testUser)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBitbucketBranchProtectionDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBitbucketBranchProtectionConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("bitbucket_branch_protection.master", "restriction_ids.%", "5"),
+					resource.TestCheckResourceAttr("bitbucket_branch_protection.master", "required_approvals", "2"),
+					resource.TestCheckResourceAttr("bitbucket_branch_protection.master", "allow_force_push", "false"),
+				),
+			},
+			{
+				ResourceName:      "bitbucket_branch_protection.master",
+				ImportState:       true,
+				ImportStateId:     fmt.Sprintf("%s/test-repo-for-branch-protection-test/master", testUser),
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestNewBranchProtectionRestrictions(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceBranchProtection().Schema, map[string]interface{}{
+		"owner":              "myteam",
+		"repository":         "terraform-code",
+		"pattern":            "master",
+		"required_approvals": 2,
+		"allow_delete":       true,
+		"merge_groups":       []interface{}{"developers"},
+	})
+
+	restrictions := newBranchProtectionRestrictions(d)
+
+	if len(restrictions) != 3 {
+		t.Fatalf("expected 3 restrictions, got %d: %v", len(restrictions), restrictions)
+	}
+
+	if restrictions["require_approvals_to_merge"].Value != 2 {
+		t.Errorf("expected 2 required approvals, got %d", restrictions["require_approvals_to_merge"].Value)
+	}
+
+	if _, ok := restrictions["force"]; !ok {
+		t.Error("expected force pushes to be restricted")
+	}
+
+	if _, ok := restrictions["delete"]; ok {
+		t.Error("expected deletes to be allowed")
+	}
+
+	merges := restrictions["restrict_merges"]
+	if len(merges.Groups) != 1 || merges.Groups[0].Slug != "developers" || merges.Groups[0].Owner.Username != "myteam" {
+		t.Errorf("expected merges to be restricted to myteam/developers, got %v", merges.Groups)
+	}
+}
+
+func TestBranchProtectionRestrictionsOnPattern(t *testing.T) {
+	restrictions := branchProtectionRestrictionsOnPattern([]BranchRestriction{
+		{ID: 1, Kind: "push", Pattern: "develop"},
+		{ID: 2, Kind: "push", BranchMatchKind: "glob", Pattern: "master"},
+		{ID: 3, Kind: "push", Pattern: "master"},
+		{ID: 4, Kind: "delete", BranchMatchKind: "branching_model", BranchType: "production"},
+		{ID: 5, Kind: "require_approvals_to_merge", Pattern: "master", Value: 2},
+		{ID: 6, Kind: "enforce_merge_checks", Pattern: "master"},
+	}, "master")
+
+	if len(restrictions) != 2 {
+		t.Fatalf("expected 2 restrictions, got %d: %v", len(restrictions), restrictions)
+	}
+
+	if restrictions["push"].ID != 2 {
+		t.Errorf("expected the first push restriction on master to be picked, got %d", restrictions["push"].ID)
+	}
+
+	if restrictions["require_approvals_to_merge"].ID != 5 {
+		t.Errorf("expected the approvals restriction to be picked, got %d", restrictions["require_approvals_to_merge"].ID)
+	}
+}
+
+func testAccCheckBitbucketBranchProtectionDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*Client)
+	rs, ok := s.RootModule().Resources["bitbucket_branch_protection.master"]
+	if !ok {
+		return fmt.Errorf("Not found %s", "bitbucket_branch_protection.master")
+	}
+
+	for key, id := range rs.Primary.Attributes {
+		if !strings.HasPrefix(key, "restriction_ids.") || key == "restriction_ids.%" {
+			continue
+		}
+
+		branchRestriction, err := getBranchRestriction(client, rs.Primary.Attributes["owner"], rs.Primary.Attributes["repository"], id)
+		if err != nil {
+			return err
+		}
+
+		if branchRestriction != nil {
+			return fmt.Errorf("BranchRestriction %s still exists", id)
+		}
+	}
+
+	return nil
+}
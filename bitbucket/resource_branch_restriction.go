@@ -4,21 +4,45 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"github.com/hashicorp/terraform/helper/customdiff"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
 	"io/ioutil"
 	"log"
 	"net/url"
+	"sort"
+	"strconv"
+	"time"
 )
 
 // BranchRestriction is the data we need to send to create a new branch restriction for the repository
 type BranchRestriction struct {
-	ID      int     `json:"id,omitempty"`
-	Kind    string  `json:"kind,omitempty"`
-	Pattern string  `json:"pattern,omitempty"`
-	Value   int     `json:"value,omitempty"`
-	Users   []User  `json:"users,omitempty"`
-	Groups  []Group `json:"groups,omitempty"`
+	ID              int     `json:"id,omitempty"`
+	Kind            string  `json:"kind,omitempty"`
+	BranchMatchKind string  `json:"branch_match_kind,omitempty"`
+	BranchType      string  `json:"branch_type,omitempty"`
+	Pattern         string  `json:"pattern,omitempty"`
+	Value           int     `json:"value,omitempty"`
+	Users           []User  `json:"users,omitempty"`
+	Groups          []Group `json:"groups,omitempty"`
+}
+
+// branchRestrictionBranchTypes are the branch types of the branching model a restriction can apply to
+var branchRestrictionBranchTypes = []string{
+	"feature",
+	"bugfix",
+	"release",
+	"hotfix",
+	"development",
+	"production",
+}
+
+// PaginatedBranchRestrictions is a paginated list that the bitbucket api returns
+type PaginatedBranchRestrictions struct {
+	Values []BranchRestriction `json:"values,omitempty"`
+	Page   int                 `json:"page,omitempty"`
+	Size   int                 `json:"size,omitempty"`
+	Next   string              `json:"next,omitempty"`
 }
 
 // User is just the user struct we want to use for BranchRestrictions
@@ -32,14 +56,54 @@ type Group struct {
 	Owner User   `json:"owner,omitempty"`
 }
 
+// branchRestrictionKind describes which arguments the Bitbucket API takes for a kind of branch restriction
+type branchRestrictionKind struct {
+	RequiresValue bool
+	AllowsUsers   bool
+}
+
+// branchRestrictionKinds is every kind of branch restriction we support. Anything the API would silently
+// ignore is rejected at plan time, otherwise it comes back empty on read and shows up as a diff forever.
+var branchRestrictionKinds = map[string]branchRestrictionKind{
+	"require_tasks_to_be_completed":               {},
+	"require_passing_builds_to_merge":             {RequiresValue: true},
+	"force":                                       {},
+	"require_all_dependencies_merged":             {},
+	"push":                                        {AllowsUsers: true},
+	"require_approvals_to_merge":                  {RequiresValue: true},
+	"enforce_merge_checks":                        {},
+	"restrict_merges":                             {AllowsUsers: true},
+	"reset_pullrequest_approvals_on_change":       {},
+	"delete":                                      {},
+	"require_default_reviewer_approvals_to_merge": {RequiresValue: true},
+}
+
+func branchRestrictionKindNames() []string {
+	names := make([]string, 0, len(branchRestrictionKinds))
+	for name := range branchRestrictionKinds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func resourceBranchRestriction() *schema.Resource {
-	return &schema.Resource{
+	resource := &schema.Resource{
 		Create: resourceBranchRestrictionsCreate,
 		Read:   resourceBranchRestrictionsRead,
 		Update: resourceBranchRestrictionsUpdate,
 		Delete: resourceBranchRestrictionsDelete,
 		Exists: resourceBranchRestrictionsExists,
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultVisibilityTimeout),
+		},
+
+		CustomizeDiff: customdiff.All(
+			resourceBranchRestrictionsKindDiff,
+			resourceBranchRestrictionsBranchMatchDiff,
+		),
+
 		Schema: map[string]*schema.Schema{
 			"owner": {
 				Type:     schema.TypeString,
@@ -51,55 +115,69 @@ func resourceBranchRestriction() *schema.Resource {
 				Required: true,
 				ForceNew: true,
 			},
-			"kind": {
-				Type:     schema.TypeString,
-				Required: true,
-				ValidateFunc: validation.StringInSlice([]string{
-					"require_tasks_to_be_completed",
-					"require_passing_builds_to_merge",
-					"force",
-					"require_all_dependencies_merged",
-					"push",
-					"require_approvals_to_merge",
-					"enforce_merge_checks",
-					"restrict_merges",
-					"reset_pullrequest_approvals_on_change",
-					"delete",
-					"require_default_reviewer_approvals_to_merge",
-				},
-					false),
-			},
-			"pattern": {
-				Type:     schema.TypeString,
-				Required: true,
-			},
-			"users": {
-				Type:     schema.TypeSet,
-				Elem:     &schema.Schema{Type: schema.TypeString},
-				Optional: true,
-				Set:      schema.HashString,
+		},
+	}
+
+	for k, v := range branchRestrictionSchema() {
+		resource.Schema[k] = v
+	}
+
+	return resource
+}
+
+// branchRestrictionSchema are the arguments of a branch restriction, shared by bitbucket_branch_restriction and
+// the restriction blocks of the resources that manage several at once
+func branchRestrictionSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"kind": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ValidateFunc: validation.StringInSlice(branchRestrictionKindNames(), false),
+		},
+		"branch_match_kind": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "glob",
+			ValidateFunc: validation.StringInSlice([]string{
+				"glob",
+				"branching_model",
 			},
-			"groups": {
-				Type: schema.TypeSet,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"owner": {
-							Type:     schema.TypeString,
-							Required: true,
-						},
-						"slug": {
-							Type:     schema.TypeString,
-							Required: true,
-						},
+				false),
+		},
+		"branch_type": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.StringInSlice(branchRestrictionBranchTypes, false),
+		},
+		"pattern": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"users": {
+			Type:     schema.TypeSet,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+			Optional: true,
+			Set:      schema.HashString,
+		},
+		"groups": {
+			Type: schema.TypeSet,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"owner": {
+						Type:     schema.TypeString,
+						Required: true,
+					},
+					"slug": {
+						Type:     schema.TypeString,
+						Required: true,
 					},
 				},
-				Optional: true,
-			},
-
-			"value": {
-				Type:     schema.TypeInt,
-				Optional: true,
 			},
+			Optional: true,
+		},
+		"value": {
+			Type:     schema.TypeInt,
+			Optional: true,
 		},
 	}
 }
@@ -120,41 +198,232 @@ func createBranchRestriction(d *schema.ResourceData) *BranchRestriction {
 	}
 
 	return &BranchRestriction{
-		Kind:    d.Get("kind").(string),
-		Pattern: d.Get("pattern").(string),
-		Value:   d.Get("value").(int),
-		Users:   users,
-		Groups:  groups,
+		Kind:            d.Get("kind").(string),
+		BranchMatchKind: d.Get("branch_match_kind").(string),
+		BranchType:      d.Get("branch_type").(string),
+		Pattern:         d.Get("pattern").(string),
+		Value:           d.Get("value").(int),
+		Users:           users,
+		Groups:          groups,
 	}
 }
 
-func resourceBranchRestrictionsCreate(d *schema.ResourceData, m interface{}) error {
-	client := m.(*Client)
-	branchRestriction := createBranchRestriction(d)
+// validateBranchMatch makes sure only the field that applies to the branch match kind is set. A glob
+// restriction needs a pattern and a branching model restriction needs a branch type.
+func validateBranchMatch(branchMatchKind, pattern, branchType string) error {
+	if branchMatchKind == "branching_model" {
+		if branchType == "" {
+			return fmt.Errorf("branch_type must be set when branch_match_kind is branching_model")
+		}
+		if pattern != "" {
+			return fmt.Errorf("pattern can not be set when branch_match_kind is branching_model")
+		}
+		return nil
+	}
 
-	bytedata, err := json.Marshal(branchRestriction)
+	if pattern == "" {
+		return fmt.Errorf("pattern must be set when branch_match_kind is %s", branchMatchKind)
+	}
+	if branchType != "" {
+		return fmt.Errorf("branch_type can only be set when branch_match_kind is branching_model")
+	}
+	return nil
+}
+
+// validateBranchRestrictionKind checks value, users and groups against what the kind of restriction accepts.
+func validateBranchRestrictionKind(kind string, value, users, groups int) error {
+	rules, ok := branchRestrictionKinds[kind]
+	if !ok {
+		return fmt.Errorf("%s is not a supported branch restriction kind", kind)
+	}
+
+	if rules.RequiresValue && value < 1 {
+		return fmt.Errorf("value must be set to a positive number for %s restrictions", kind)
+	}
+	if !rules.RequiresValue && value != 0 {
+		return fmt.Errorf("value can not be set for %s restrictions", kind)
+	}
+	if !rules.AllowsUsers && (users > 0 || groups > 0) {
+		return fmt.Errorf("users and groups can not be set for %s restrictions", kind)
+	}
+	return nil
+}
+
+func resourceBranchRestrictionsKindDiff(d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("kind") || !d.NewValueKnown("value") || !d.NewValueKnown("users") || !d.NewValueKnown("groups") {
+		return nil
+	}
+
+	return validateBranchRestrictionKind(
+		d.Get("kind").(string),
+		d.Get("value").(int),
+		d.Get("users").(*schema.Set).Len(),
+		d.Get("groups").(*schema.Set).Len(),
+	)
+}
+
+func resourceBranchRestrictionsBranchMatchDiff(d *schema.ResourceDiff, m interface{}) error {
+	// Values coming from other resources are not known until apply, so we can only check literals here.
+	if !d.NewValueKnown("branch_match_kind") || !d.NewValueKnown("pattern") || !d.NewValueKnown("branch_type") {
+		return nil
+	}
+
+	return validateBranchMatch(
+		d.Get("branch_match_kind").(string),
+		d.Get("pattern").(string),
+		d.Get("branch_type").(string),
+	)
+}
 
+// postBranchRestriction creates the branch restriction on the repository and returns what bitbucket stored, once it
+// shows up when it is read back
+func postBranchRestriction(client *Client, owner, repository string, branchRestriction *BranchRestriction, timeout time.Duration) (*BranchRestriction, error) {
+	bytedata, err := json.Marshal(branchRestriction)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	branchRestrictionReq, err := client.Post(fmt.Sprintf("2.0/repositories/%s/%s/branch-restrictions",
-		d.Get("owner").(string),
-		d.Get("repository").(string),
+		owner,
+		repository,
 	), bytes.NewBuffer(bytedata))
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	body, readerr := ioutil.ReadAll(branchRestrictionReq.Body)
 	if readerr != nil {
-		return readerr
+		return nil, readerr
+	}
+
+	var created BranchRestriction
+	decodeerr := json.Unmarshal(body, &created)
+	if decodeerr != nil {
+		return nil, decodeerr
+	}
+
+	id := strconv.Itoa(created.ID)
+	err = waitUntilVisible(fmt.Sprintf("branch restriction %s on %s/%s", id, owner, repository), timeout, func() (bool, error) {
+		branchRestriction, err := getBranchRestriction(client, owner, repository, id)
+		return branchRestriction != nil, err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// getBranchRestriction fetches a single branch restriction, it returns nil if the restriction does not exist
+func getBranchRestriction(client *Client, owner, repository, id string) (*BranchRestriction, error) {
+	branchRestrictionsReq, err := client.Get(fmt.Sprintf("2.0/repositories/%s/%s/branch-restrictions/%s",
+		owner,
+		repository,
+		url.PathEscape(id),
+	))
+
+	if branchRestrictionsReq != nil && branchRestrictionsReq.StatusCode == 404 {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	body, readerr := ioutil.ReadAll(branchRestrictionsReq.Body)
+	if readerr != nil {
+		return nil, readerr
 	}
 
+	var branchRestriction BranchRestriction
 	decodeerr := json.Unmarshal(body, &branchRestriction)
 	if decodeerr != nil {
-		return decodeerr
+		return nil, decodeerr
+	}
+
+	return &branchRestriction, nil
+}
+
+// listBranchRestrictions fetches every page of branch restrictions on the repository
+func listBranchRestrictions(client *Client, owner, repository string) ([]BranchRestriction, error) {
+	resourceURL := fmt.Sprintf("2.0/repositories/%s/%s/branch-restrictions",
+		owner,
+		repository,
+	)
+
+	var branchRestrictions []BranchRestriction
+
+	for {
+		branchRestrictionsReq, err := client.Get(resourceURL)
+		if err != nil {
+			return nil, err
+		}
+
+		var page PaginatedBranchRestrictions
+		decoder := json.NewDecoder(branchRestrictionsReq.Body)
+		err = decoder.Decode(&page)
+		branchRestrictionsReq.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		branchRestrictions = append(branchRestrictions, page.Values...)
+
+		if page.Next == "" {
+			break
+		}
+
+		resourceURL = nextPageEndpoint(page.Next)
+	}
+
+	return branchRestrictions, nil
+}
+
+// putBranchRestriction replaces an existing branch restriction
+func putBranchRestriction(client *Client, owner, repository, id string, branchRestriction *BranchRestriction) error {
+	payload, err := json.Marshal(branchRestriction)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Put(fmt.Sprintf("2.0/repositories/%s/%s/branch-restrictions/%s",
+		owner,
+		repository,
+		url.PathEscape(id),
+	), bytes.NewBuffer(payload))
+
+	return err
+}
+
+// deleteBranchRestriction removes a branch restriction, one that is already gone is not an error
+func deleteBranchRestriction(client *Client, owner, repository, id string) error {
+	resp, err := client.Delete(fmt.Sprintf("2.0/repositories/%s/%s/branch-restrictions/%s",
+		owner,
+		repository,
+		url.PathEscape(id),
+	))
+
+	if resp != nil && resp.StatusCode == 404 {
+		return nil
+	}
+
+	return err
+}
+
+func resourceBranchRestrictionsCreate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	branchRestriction, err := postBranchRestriction(client,
+		d.Get("owner").(string),
+		d.Get("repository").(string),
+		createBranchRestriction(d),
+		d.Timeout(schema.TimeoutCreate),
+	)
+
+	if err != nil {
+		return err
 	}
 
 	d.SetId(string(fmt.Sprintf("%v", branchRestriction.ID)))
@@ -165,50 +434,46 @@ func resourceBranchRestrictionsCreate(d *schema.ResourceData, m interface{}) err
 func resourceBranchRestrictionsRead(d *schema.ResourceData, m interface{}) error {
 	client := m.(*Client)
 
-	branchRestrictionsReq, _ := client.Get(fmt.Sprintf("2.0/repositories/%s/%s/branch-restrictions/%s",
+	log.Printf("ID: %s", url.PathEscape(d.Id()))
+
+	branchRestriction, err := getBranchRestriction(client,
 		d.Get("owner").(string),
 		d.Get("repository").(string),
-		url.PathEscape(d.Id()),
-	))
+		d.Id(),
+	)
 
-	log.Printf("ID: %s", url.PathEscape(d.Id()))
-
-	if branchRestrictionsReq.StatusCode == 200 {
-		var branchRestriction BranchRestriction
-		body, readerr := ioutil.ReadAll(branchRestrictionsReq.Body)
-		if readerr != nil {
-			return readerr
-		}
+	if err != nil {
+		return err
+	}
 
-		decodeerr := json.Unmarshal(body, &branchRestriction)
-		if decodeerr != nil {
-			return decodeerr
-		}
+	if branchRestriction == nil {
+		d.SetId("")
+		return nil
+	}
 
-		d.SetId(string(fmt.Sprintf("%v", branchRestriction.ID)))
-		d.Set("kind", branchRestriction.Kind)
-		d.Set("pattern", branchRestriction.Pattern)
-		d.Set("value", branchRestriction.Value)
-		d.Set("users", branchRestriction.Users)
-		d.Set("groups", branchRestriction.Groups)
+	d.SetId(string(fmt.Sprintf("%v", branchRestriction.ID)))
+	d.Set("kind", branchRestriction.Kind)
+	if branchRestriction.BranchMatchKind != "" {
+		d.Set("branch_match_kind", branchRestriction.BranchMatchKind)
 	}
+	d.Set("branch_type", branchRestriction.BranchType)
+	d.Set("pattern", branchRestriction.Pattern)
+	d.Set("value", branchRestriction.Value)
+	d.Set("users", branchRestriction.Users)
+	d.Set("groups", branchRestriction.Groups)
 
 	return nil
 }
 
 func resourceBranchRestrictionsUpdate(d *schema.ResourceData, m interface{}) error {
 	client := m.(*Client)
-	branchRestriction := createBranchRestriction(d)
-	payload, err := json.Marshal(branchRestriction)
-	if err != nil {
-		return err
-	}
 
-	_, err = client.Put(fmt.Sprintf("2.0/repositories/%s/%s/branch-restrictions/%s",
+	err := putBranchRestriction(client,
 		d.Get("owner").(string),
 		d.Get("repository").(string),
-		url.PathEscape(d.Id()),
-	), bytes.NewBuffer(payload))
+		d.Id(),
+		createBranchRestriction(d),
+	)
 
 	if err != nil {
 		return err
@@ -219,13 +484,12 @@ func resourceBranchRestrictionsUpdate(d *schema.ResourceData, m interface{}) err
 
 func resourceBranchRestrictionsDelete(d *schema.ResourceData, m interface{}) error {
 	client := m.(*Client)
-	_, err := client.Delete(fmt.Sprintf("2.0/repositories/%s/%s/branch-restrictions/%s",
+
+	return deleteBranchRestriction(client,
 		d.Get("owner").(string),
 		d.Get("repository").(string),
-		url.PathEscape(d.Id()),
-	))
-
-	return err
+		d.Id(),
+	)
 }
 
 func resourceBranchRestrictionsExists(d *schema.ResourceData, m interface{}) (bool, error) {
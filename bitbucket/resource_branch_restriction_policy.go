@@ -0,0 +1,458 @@
+package bitbucket
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceBranchRestrictionPolicy() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceBranchRestrictionPolicyCreate,
+		Read:   resourceBranchRestrictionPolicyRead,
+		Update: resourceBranchRestrictionPolicyUpdate,
+		Delete: resourceBranchRestrictionPolicyDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceBranchRestrictionPolicyImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultVisibilityTimeout),
+			Update: schema.DefaultTimeout(defaultVisibilityTimeout),
+		},
+
+		CustomizeDiff: resourceBranchRestrictionPolicyDiff,
+
+		Schema: map[string]*schema.Schema{
+			"owner": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"project_key": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"query": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"restriction": {
+				Type:     schema.TypeSet,
+				Required: true,
+				Elem:     branchRestrictionBlock(),
+			},
+			"repository_status": {
+				Type:     schema.TypeMap,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Computed: true,
+			},
+			"created_restriction_ids": {
+				Type:     schema.TypeMap,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Computed: true,
+			},
+		},
+	}
+}
+
+// branchRestrictionPolicyQuery builds the BBQL query that selects the repositories the policy applies to
+func branchRestrictionPolicyQuery(projectKey, query string) string {
+	var clauses []string
+
+	if projectKey != "" {
+		clauses = append(clauses, fmt.Sprintf("project.key=%q", projectKey))
+	}
+
+	if query != "" {
+		clauses = append(clauses, fmt.Sprintf("(%s)", query))
+	}
+
+	return strings.Join(clauses, " AND ")
+}
+
+// branchRestrictionPolicyID is the same for every policy on the same repositories, without putting the query itself
+// in the id
+func branchRestrictionPolicyID(owner, projectKey, query string) string {
+	return fmt.Sprintf("%s/%d", owner, hashcode.String(branchRestrictionPolicyQuery(projectKey, query)))
+}
+
+// createdBranchRestrictionIDs reads the ids of the restrictions the policy created out of the comma separated list
+// it keeps per repository
+func createdBranchRestrictionIDs(createdRestrictionIDs map[string]interface{}, repository string) map[string]bool {
+	ids := make(map[string]bool)
+
+	if list, ok := createdRestrictionIDs[repository]; ok && list.(string) != "" {
+		for _, id := range strings.Split(list.(string), ",") {
+			ids[id] = true
+		}
+	}
+
+	return ids
+}
+
+func joinBranchRestrictionIDs(ids map[string]bool) string {
+	list := make([]string, 0, len(ids))
+	for id := range ids {
+		list = append(list, id)
+	}
+
+	sort.Strings(list)
+	return strings.Join(list, ",")
+}
+
+func sortedBranchRestrictionMembers(branchRestriction *BranchRestriction) []string {
+	members := make([]string, 0, len(branchRestriction.Users)+len(branchRestriction.Groups))
+
+	for _, user := range branchRestriction.Users {
+		members = append(members, "user:"+user.Username)
+	}
+
+	for _, group := range branchRestriction.Groups {
+		members = append(members, "group:"+group.Owner.Username+"/"+group.Slug)
+	}
+
+	sort.Strings(members)
+	return members
+}
+
+// branchRestrictionApplied tells if one of the existing restrictions is the same as the wanted one
+func branchRestrictionApplied(want *BranchRestriction, existing []BranchRestriction) bool {
+	key := branchRestrictionKey(want)
+	wantMembers := strings.Join(sortedBranchRestrictionMembers(want), ",")
+
+	for _, branchRestriction := range existing {
+		if branchRestrictionKey(&branchRestriction) != key {
+			continue
+		}
+
+		if branchRestriction.Value == want.Value && strings.Join(sortedBranchRestrictionMembers(&branchRestriction), ",") == wantMembers {
+			return true
+		}
+	}
+
+	return false
+}
+
+func resourceBranchRestrictionPolicyDiff(d *schema.ResourceDiff, m interface{}) error {
+	if d.NewValueKnown("project_key") && d.NewValueKnown("query") && d.Get("project_key").(string) == "" && d.Get("query").(string) == "" {
+		return fmt.Errorf("one of project_key or query must be set")
+	}
+
+	if d.NewValueKnown("restriction") {
+		if err := validateBranchRestrictionBlocks(d.Get("restriction").(*schema.Set)); err != nil {
+			return err
+		}
+	}
+
+	if d.Id() == "" {
+		return nil
+	}
+
+	// Any change to the restrictions can create or delete some.
+	if d.HasChange("restriction") {
+		if err := d.SetNewComputed("created_restriction_ids"); err != nil {
+			return err
+		}
+	}
+
+	// Repositories that were added since the last apply, or whose restrictions were changed in the UI, are
+	// not applied yet. Marking the status as unknown gives a plan that brings them in line.
+	for repository, status := range d.Get("repository_status").(map[string]interface{}) {
+		if status.(string) != "applied" {
+			log.Printf("[DEBUG] Branch restriction policy %s is %s on %s", d.Id(), status, repository)
+
+			if err := d.SetNewComputed("created_restriction_ids"); err != nil {
+				return err
+			}
+			return d.SetNewComputed("repository_status")
+		}
+	}
+
+	return nil
+}
+
+// stateCreatedRestrictionIDs are the ids of the restrictions the policy created per repository. The planned ids are
+// unknown while the policy changes, the state has the ones we created.
+func stateCreatedRestrictionIDs(d *schema.ResourceData) map[string]interface{} {
+	createdRestrictionIDs, _ := d.GetChange("created_restriction_ids")
+	return createdRestrictionIDs.(map[string]interface{})
+}
+
+// applyBranchRestrictionPolicy creates or updates the declared restrictions on every matching repository, and adds
+// the ones it created to createdRestrictionIDs. It keeps going when a repository fails so one broken repository does
+// not hold back the rest of the project.
+func applyBranchRestrictionPolicy(d *schema.ResourceData, client *Client, createdRestrictionIDs map[string]interface{}, timeout string) error {
+	owner := d.Get("owner").(string)
+
+	repositories, err := listRepositories(client, owner, branchRestrictionPolicyQuery(
+		d.Get("project_key").(string),
+		d.Get("query").(string),
+	))
+
+	if err != nil {
+		return err
+	}
+
+	var failures []string
+
+	// Repositories that no longer match keep their entry, the restrictions we created there are still ours.
+	for _, repository := range repositories {
+		created, err := applyBranchRestrictionsToRepository(d, client, owner, repository.Slug,
+			createdBranchRestrictionIDs(createdRestrictionIDs, repository.Slug), d.Timeout(timeout))
+
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", repository.Slug, err))
+		}
+
+		if len(created) > 0 {
+			createdRestrictionIDs[repository.Slug] = joinBranchRestrictionIDs(created)
+		} else {
+			delete(createdRestrictionIDs, repository.Slug)
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to apply branch restrictions to %d repositories:\n%s",
+			len(failures),
+			strings.Join(failures, "\n"),
+		)
+	}
+
+	return nil
+}
+
+// applyBranchRestrictionsToRepository brings the restrictions of a repository in line with the policy. It returns
+// the ids of the restrictions the policy created there, starting from the ones it created before that still exist.
+// Restrictions that were already there are updated but not recorded, removing the policy leaves them in place.
+func applyBranchRestrictionsToRepository(d *schema.ResourceData, client *Client, owner, repository string, created map[string]bool, timeout time.Duration) (map[string]bool, error) {
+	existing, err := listBranchRestrictions(client, owner, repository)
+	if err != nil {
+		return created, err
+	}
+
+	stillCreated := make(map[string]bool)
+	existingByKey := make(map[string]BranchRestriction)
+	for _, branchRestriction := range existing {
+		existingByKey[branchRestrictionKey(&branchRestriction)] = branchRestriction
+
+		if id := strconv.Itoa(branchRestriction.ID); created[id] {
+			stillCreated[id] = true
+		}
+	}
+	created = stillCreated
+
+	for _, item := range d.Get("restriction").(*schema.Set).List() {
+		branchRestriction := newBranchRestrictionFromMap(item.(map[string]interface{}))
+
+		if branchRestrictionApplied(branchRestriction, existing) {
+			continue
+		}
+
+		if current, ok := existingByKey[branchRestrictionKey(branchRestriction)]; ok {
+			if err := putBranchRestriction(client, owner, repository, strconv.Itoa(current.ID), branchRestriction); err != nil {
+				return created, err
+			}
+			continue
+		}
+
+		posted, err := postBranchRestriction(client, owner, repository, branchRestriction, timeout)
+		if err != nil {
+			return created, err
+		}
+		created[strconv.Itoa(posted.ID)] = true
+	}
+
+	return created, nil
+}
+
+// removeBranchRestrictionsFromRepositories deletes the given restrictions from the repositories the policy was
+// applied to, as long as the policy created them. It updates createdRestrictionIDs as it goes so a failure halfway
+// does not lose track of the rest.
+func removeBranchRestrictionsFromRepositories(client *Client, owner string, createdRestrictionIDs map[string]interface{}, restrictions *schema.Set) error {
+	keys := make(map[string]bool)
+	for _, item := range restrictions.List() {
+		keys[branchRestrictionKey(newBranchRestrictionFromMap(item.(map[string]interface{})))] = true
+	}
+
+	for repository := range createdRestrictionIDs {
+		created := createdBranchRestrictionIDs(createdRestrictionIDs, repository)
+
+		existing, err := listBranchRestrictions(client, owner, repository)
+
+		// Deleted or renamed since the policy applied, its restrictions went with it.
+		if isNotFound(err) {
+			delete(createdRestrictionIDs, repository)
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		for _, branchRestriction := range existing {
+			id := strconv.Itoa(branchRestriction.ID)
+			if !created[id] || !keys[branchRestrictionKey(&branchRestriction)] {
+				continue
+			}
+
+			if err := deleteBranchRestriction(client, owner, repository, id); err != nil {
+				return err
+			}
+
+			delete(created, id)
+			if len(created) > 0 {
+				createdRestrictionIDs[repository] = joinBranchRestrictionIDs(created)
+			} else {
+				delete(createdRestrictionIDs, repository)
+			}
+		}
+	}
+
+	return nil
+}
+
+func resourceBranchRestrictionPolicyCreate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	d.SetId(branchRestrictionPolicyID(
+		d.Get("owner").(string),
+		d.Get("project_key").(string),
+		d.Get("query").(string),
+	))
+
+	createdRestrictionIDs := make(map[string]interface{})
+	err := applyBranchRestrictionPolicy(d, client, createdRestrictionIDs, schema.TimeoutCreate)
+	d.Set("created_restriction_ids", createdRestrictionIDs)
+
+	if err != nil {
+		// Record which repositories made it so the next plan only has to deal with the rest.
+		if readErr := resourceBranchRestrictionPolicyRead(d, m); readErr != nil {
+			log.Printf("[WARN] Failed to read branch restriction policy %s: %s", d.Id(), readErr)
+		}
+		return err
+	}
+
+	return resourceBranchRestrictionPolicyRead(d, m)
+}
+
+func resourceBranchRestrictionPolicyRead(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+	owner := d.Get("owner").(string)
+
+	repositories, err := listRepositories(client, owner, branchRestrictionPolicyQuery(
+		d.Get("project_key").(string),
+		d.Get("query").(string),
+	))
+
+	if err != nil {
+		return err
+	}
+
+	status := make(map[string]string)
+
+	for _, repository := range repositories {
+		existing, err := listBranchRestrictions(client, owner, repository.Slug)
+		if err != nil {
+			status[repository.Slug] = fmt.Sprintf("error: %s", err)
+			continue
+		}
+
+		status[repository.Slug] = "applied"
+		for _, item := range d.Get("restriction").(*schema.Set).List() {
+			if !branchRestrictionApplied(newBranchRestrictionFromMap(item.(map[string]interface{})), existing) {
+				status[repository.Slug] = "pending"
+				break
+			}
+		}
+	}
+
+	d.Set("repository_status", status)
+
+	return nil
+}
+
+func resourceBranchRestrictionPolicyUpdate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+	createdRestrictionIDs := stateCreatedRestrictionIDs(d)
+
+	if d.HasChange("restriction") {
+		o, n := d.GetChange("restriction")
+
+		removed := o.(*schema.Set).Difference(n.(*schema.Set))
+		kept := make(map[string]bool)
+		for _, item := range n.(*schema.Set).List() {
+			kept[branchRestrictionKey(newBranchRestrictionFromMap(item.(map[string]interface{})))] = true
+		}
+
+		// A restriction whose value or members changed shows up in both sets, it is updated rather than removed.
+		for _, item := range removed.List() {
+			if kept[branchRestrictionKey(newBranchRestrictionFromMap(item.(map[string]interface{})))] {
+				removed.Remove(item)
+			}
+		}
+
+		err := removeBranchRestrictionsFromRepositories(client, d.Get("owner").(string), createdRestrictionIDs, removed)
+		d.Set("created_restriction_ids", createdRestrictionIDs)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	err := applyBranchRestrictionPolicy(d, client, createdRestrictionIDs, schema.TimeoutUpdate)
+	d.Set("created_restriction_ids", createdRestrictionIDs)
+
+	if err != nil {
+		if readErr := resourceBranchRestrictionPolicyRead(d, m); readErr != nil {
+			log.Printf("[WARN] Failed to read branch restriction policy %s: %s", d.Id(), readErr)
+		}
+		return err
+	}
+
+	return resourceBranchRestrictionPolicyRead(d, m)
+}
+
+func resourceBranchRestrictionPolicyDelete(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	createdRestrictionIDs := stateCreatedRestrictionIDs(d)
+	err := removeBranchRestrictionsFromRepositories(client, d.Get("owner").(string), createdRestrictionIDs, d.Get("restriction").(*schema.Set))
+	d.Set("created_restriction_ids", createdRestrictionIDs)
+
+	return err
+}
+
+// resourceBranchRestrictionPolicyImport takes `owner/project_key` or `owner/project_key/query`, where the project key
+// can be left empty. The restrictions come from the configuration, and the ones that are already in place were not
+// created by the policy so they are not removed with it.
+func resourceBranchRestrictionPolicyImport(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	idparts := strings.SplitN(d.Id(), "/", 3)
+	if len(idparts) < 2 || idparts[0] == "" {
+		return nil, fmt.Errorf("Incorrect ID format, should match `owner/project_key` or `owner/project_key/query`")
+	}
+
+	query := ""
+	if len(idparts) == 3 {
+		query = idparts[2]
+	}
+
+	if idparts[1] == "" && query == "" {
+		return nil, fmt.Errorf("one of project_key or query must be set")
+	}
+
+	d.Set("owner", idparts[0])
+	d.Set("project_key", idparts[1])
+	d.Set("query", query)
+	d.SetId(branchRestrictionPolicyID(idparts[0], idparts[1], query))
+
+	return []*schema.ResourceData{d}, nil
+}
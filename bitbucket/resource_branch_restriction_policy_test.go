@@ -0,0 +1,134 @@
+package bitbucket
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccBitbucketBranchRestrictionPolicy_basic(t *testing.T) {
+	testTeam := os.Getenv("BITBUCKET_TEAM")
+	testAccBitbucketBranchRestrictionPolicyConfig := fmt.Sprintf(`
+		resource "bitbucket_project" "test_project" {
+			owner = "%s"
+			name = "test-project-for-branch-restriction-policy-test"
+			key = "TFBRP"
+		}
+		resource "bitbucket_repository" "test_repo" {
+			owner = "%s"
+			name = "test-repo-for-branch-restriction-policy-test"
+			project_key = "${bitbucket_project.test_project.key}"
+		}
+		resource "bitbucket_branch_restriction_policy" "test_policy" {
+			owner = "%s"
+			query = "project.key=\"${bitbucket_project.test_project.key}\" AND slug=\"${bitbucket_repository.test_repo.name}\""
+
+			restriction {
+				kind = "require_approvals_to_merge"
+				pattern = "master"
+				value = 2
+			}
+		}
+	`, testTeam, testTeam, testTeam)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBitbucketBranchRestrictionPolicyConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("bitbucket_branch_restriction_policy.test_policy", "repository_status.%", "1"),
+					resource.TestCheckResourceAttr("bitbucket_branch_restriction_policy.test_policy", "repository_status.test-repo-for-branch-restriction-policy-test", "applied"),
+				),
+			},
+			{
+				ResourceName:      "bitbucket_branch_restriction_policy.test_policy",
+				ImportState:       true,
+				ImportStateId:     fmt.Sprintf("%s//project.key=\"TFBRP\" AND slug=\"test-repo-for-branch-restriction-policy-test\"", testTeam),
+				ImportStateVerify: true,
+				// The restrictions come from the configuration, and an imported policy did not create any.
+				ImportStateVerifyIgnore: []string{"restriction", "created_restriction_ids"},
+			},
+		},
+	})
+}
+
+func TestBranchRestrictionPolicyQuery(t *testing.T) {
+	cases := map[string][]string{
+		`project.key="PLAT"`:                        {"PLAT", ""},
+		`(name ~ "service")`:                        {"", `name ~ "service"`},
+		`project.key="PLAT" AND (name ~ "service")`: {"PLAT", `name ~ "service"`},
+	}
+
+	for expected, args := range cases {
+		if query := branchRestrictionPolicyQuery(args[0], args[1]); query != expected {
+			t.Errorf("expected %s, got %s", expected, query)
+		}
+	}
+}
+
+func TestBranchRestrictionPolicyID(t *testing.T) {
+	id := branchRestrictionPolicyID("myteam", "PLAT", `name ~ "service"`)
+
+	if id != branchRestrictionPolicyID("myteam", "PLAT", `name ~ "service"`) {
+		t.Errorf("expected the id of a policy to be the same every time")
+	}
+
+	if strings.Contains(id, "service") {
+		t.Errorf("expected the query to be left out of the id, got %s", id)
+	}
+
+	if id == branchRestrictionPolicyID("myteam", "PLAT", "") {
+		t.Errorf("expected policies on different repositories to have different ids")
+	}
+}
+
+func TestCreatedBranchRestrictionIDs(t *testing.T) {
+	createdRestrictionIDs := map[string]interface{}{
+		"terraform-code": "12,3",
+		"empty":          "",
+	}
+
+	ids := createdBranchRestrictionIDs(createdRestrictionIDs, "terraform-code")
+	if len(ids) != 2 || !ids["3"] || !ids["12"] {
+		t.Errorf("expected ids 3 and 12, got %v", ids)
+	}
+
+	if ids := createdBranchRestrictionIDs(createdRestrictionIDs, "empty"); len(ids) != 0 {
+		t.Errorf("expected no ids, got %v", ids)
+	}
+
+	if joined := joinBranchRestrictionIDs(ids); joined != "12,3" {
+		t.Errorf("expected 12,3, got %s", joined)
+	}
+}
+
+func TestBranchRestrictionApplied(t *testing.T) {
+	want := &BranchRestriction{
+		Kind:    "push",
+		Pattern: "master",
+		Users:   []User{{Username: "gob"}, {Username: "buster"}},
+	}
+
+	existing := []BranchRestriction{
+		{ID: 1, Kind: "force", BranchMatchKind: "glob", Pattern: "master"},
+		{ID: 2, Kind: "push", BranchMatchKind: "glob", Pattern: "master", Users: []User{{Username: "buster"}, {Username: "gob"}}},
+	}
+
+	if !branchRestrictionApplied(want, existing) {
+		t.Error("expected the push restriction to be applied")
+	}
+
+	want.Users = want.Users[:1]
+	if branchRestrictionApplied(want, existing) {
+		t.Error("expected a push restriction with different users not to be applied")
+	}
+
+	if branchRestrictionApplied(&BranchRestriction{Kind: "delete", Pattern: "master"}, existing) {
+		t.Error("expected a missing restriction not to be applied")
+	}
+}
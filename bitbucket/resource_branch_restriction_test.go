@@ -73,3 +73,97 @@ func testAccCheckBitbucketBranchRestrictionExists(n string, branchRestriction *B
 		return nil
 	}
 }
+
+func TestAccBitbucketBranchRestriction_branchingModel(t *testing.T) {
+	var branchRestriction BranchRestriction
+
+	testUser := os.Getenv("BITBUCKET_USERNAME")
+	testAccBitbucketBranchRestrictionConfig := fmt.Sprintf(`
+		resource "bitbucket_repository" "test_repo" {
+			owner = "%s"
+			name = "test-repo-for-branch-restriction-test"
+		}
+		resource "bitbucket_branch_restriction" "test_repo_branch_restriction" {
+			owner = "%s"
+			repository = "${bitbucket_repository.test_repo.name}"
+			kind = "delete"
+			branch_match_kind = "branching_model"
+			branch_type = "release"
+		}
+	`, testUser, testUser)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBitbucketBranchRestrictionDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBitbucketBranchRestrictionConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBitbucketBranchRestrictionExists("bitbucket_branch_restriction.test_repo_branch_restriction", &branchRestriction),
+					resource.TestCheckResourceAttr("bitbucket_branch_restriction.test_repo_branch_restriction", "branch_match_kind", "branching_model"),
+					resource.TestCheckResourceAttr("bitbucket_branch_restriction.test_repo_branch_restriction", "branch_type", "release"),
+				),
+			},
+		},
+	})
+}
+
+func TestValidateBranchMatch(t *testing.T) {
+	cases := []struct {
+		branchMatchKind string
+		pattern         string
+		branchType      string
+		valid           bool
+	}{
+		{"glob", "master", "", true},
+		{"glob", "", "", false},
+		{"glob", "master", "release", false},
+		{"branching_model", "", "release", true},
+		{"branching_model", "", "", false},
+		{"branching_model", "master", "release", false},
+	}
+
+	for _, c := range cases {
+		err := validateBranchMatch(c.branchMatchKind, c.pattern, c.branchType)
+		if c.valid && err != nil {
+			t.Errorf("expected %s/%q/%q to be valid, got %s", c.branchMatchKind, c.pattern, c.branchType, err)
+		}
+		if !c.valid && err == nil {
+			t.Errorf("expected %s/%q/%q to be invalid", c.branchMatchKind, c.pattern, c.branchType)
+		}
+	}
+}
+
+func TestValidateBranchRestrictionKind(t *testing.T) {
+	for kind, rules := range branchRestrictionKinds {
+		value := 0
+		if rules.RequiresValue {
+			value = 2
+		}
+
+		if err := validateBranchRestrictionKind(kind, value, 0, 0); err != nil {
+			t.Errorf("expected %s with value %d to be valid, got %s", kind, value, err)
+		}
+
+		if rules.RequiresValue {
+			if err := validateBranchRestrictionKind(kind, 0, 0, 0); err == nil {
+				t.Errorf("expected %s without a value to be invalid", kind)
+			}
+		} else if err := validateBranchRestrictionKind(kind, 1, 0, 0); err == nil {
+			t.Errorf("expected %s with a value to be invalid", kind)
+		}
+
+		err := validateBranchRestrictionKind(kind, value, 1, 1)
+		if rules.AllowsUsers && err != nil {
+			t.Errorf("expected %s with users and groups to be valid, got %s", kind, err)
+		}
+		if !rules.AllowsUsers && err == nil {
+			t.Errorf("expected %s with users and groups to be invalid", kind)
+		}
+	}
+
+	if err := validateBranchRestrictionKind("not_a_kind", 0, 0, 0); err == nil {
+		t.Error("expected an unknown kind to be invalid")
+	}
+}
@@ -0,0 +1,312 @@
+package bitbucket
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceRepositoryBranchRestrictions() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceRepositoryBranchRestrictionsCreate,
+		Read:   resourceRepositoryBranchRestrictionsRead,
+		Update: resourceRepositoryBranchRestrictionsUpdate,
+		Delete: resourceRepositoryBranchRestrictionsDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceRepositoryBranchRestrictionsImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultVisibilityTimeout),
+			Update: schema.DefaultTimeout(defaultVisibilityTimeout),
+		},
+
+		CustomizeDiff: resourceRepositoryBranchRestrictionsDiff,
+
+		Schema: map[string]*schema.Schema{
+			"owner": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"repository": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"restriction": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     branchRestrictionBlock(),
+			},
+		},
+	}
+}
+
+// branchRestrictionBlock is the schema of a restriction block, it takes the same arguments as a
+// bitbucket_branch_restriction without the repository
+func branchRestrictionBlock() *schema.Resource {
+	return &schema.Resource{
+		Schema: branchRestrictionSchema(),
+	}
+}
+
+// newBranchRestrictionFromMap turns a restriction block into the payload we send to bitbucket
+func newBranchRestrictionFromMap(m map[string]interface{}) *BranchRestriction {
+	branchRestriction := &BranchRestriction{
+		Kind:            m["kind"].(string),
+		BranchMatchKind: m["branch_match_kind"].(string),
+		BranchType:      m["branch_type"].(string),
+		Pattern:         m["pattern"].(string),
+		Value:           m["value"].(int),
+		Users:           make([]User, 0),
+		Groups:          make([]Group, 0),
+	}
+
+	for _, item := range m["users"].(*schema.Set).List() {
+		branchRestriction.Users = append(branchRestriction.Users, User{Username: item.(string)})
+	}
+
+	for _, item := range m["groups"].(*schema.Set).List() {
+		group := item.(map[string]interface{})
+		branchRestriction.Groups = append(branchRestriction.Groups, Group{Owner: User{Username: group["owner"].(string)}, Slug: group["slug"].(string)})
+	}
+
+	return branchRestriction
+}
+
+// flattenBranchRestriction turns a branch restriction from bitbucket into a restriction block
+func flattenBranchRestriction(branchRestriction BranchRestriction) map[string]interface{} {
+	branchMatchKind := branchRestriction.BranchMatchKind
+	if branchMatchKind == "" {
+		branchMatchKind = "glob"
+	}
+
+	users := make([]interface{}, 0, len(branchRestriction.Users))
+	for _, user := range branchRestriction.Users {
+		users = append(users, user.Username)
+	}
+
+	groups := make([]interface{}, 0, len(branchRestriction.Groups))
+	for _, group := range branchRestriction.Groups {
+		groups = append(groups, map[string]interface{}{
+			"owner": group.Owner.Username,
+			"slug":  group.Slug,
+		})
+	}
+
+	return map[string]interface{}{
+		"kind":              branchRestriction.Kind,
+		"branch_match_kind": branchMatchKind,
+		"branch_type":       branchRestriction.BranchType,
+		"pattern":           branchRestriction.Pattern,
+		"value":             branchRestriction.Value,
+		"users":             users,
+		"groups":            groups,
+	}
+}
+
+// branchRestrictionKey identifies a restriction by what it restricts rather than by its numeric ID, so a declared
+// restriction can be matched up with the one bitbucket already has.
+func branchRestrictionKey(branchRestriction *BranchRestriction) string {
+	branchMatchKind := branchRestriction.BranchMatchKind
+	if branchMatchKind == "" {
+		branchMatchKind = "glob"
+	}
+
+	return strings.Join([]string{
+		branchRestriction.Kind,
+		branchMatchKind,
+		branchRestriction.Pattern,
+		branchRestriction.BranchType,
+	}, "|")
+}
+
+func resourceRepositoryBranchRestrictionsDiff(d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("restriction") {
+		return nil
+	}
+
+	return validateBranchRestrictionBlocks(d.Get("restriction").(*schema.Set))
+}
+
+// validateBranchRestrictionBlocks applies the bitbucket_branch_restriction rules to every restriction block and
+// makes sure no restriction is declared twice.
+func validateBranchRestrictionBlocks(restrictions *schema.Set) error {
+	seen := make(map[string]bool)
+
+	for _, item := range restrictions.List() {
+		restriction := item.(map[string]interface{})
+		branchRestriction := newBranchRestrictionFromMap(restriction)
+
+		err := validateBranchMatch(branchRestriction.BranchMatchKind, branchRestriction.Pattern, branchRestriction.BranchType)
+		if err != nil {
+			return err
+		}
+
+		err = validateBranchRestrictionKind(
+			branchRestriction.Kind,
+			branchRestriction.Value,
+			len(branchRestriction.Users),
+			len(branchRestriction.Groups),
+		)
+		if err != nil {
+			return err
+		}
+
+		key := branchRestrictionKey(branchRestriction)
+		if seen[key] {
+			return fmt.Errorf("%s restriction on %s%s is declared more than once",
+				branchRestriction.Kind,
+				branchRestriction.Pattern,
+				branchRestriction.BranchType,
+			)
+		}
+		seen[key] = true
+	}
+
+	return nil
+}
+
+// syncRepositoryBranchRestrictions makes the restrictions on the repository match the declared ones, updating
+// the ones that already exist, creating the missing ones and deleting everything else.
+func syncRepositoryBranchRestrictions(d *schema.ResourceData, client *Client, timeout string) error {
+	owner := d.Get("owner").(string)
+	repository := d.Get("repository").(string)
+
+	existing, err := listBranchRestrictions(client, owner, repository)
+	if err != nil {
+		return err
+	}
+
+	existingByKey := make(map[string]BranchRestriction)
+	var unmanaged []BranchRestriction
+
+	for _, branchRestriction := range existing {
+		key := branchRestrictionKey(&branchRestriction)
+		if _, ok := existingByKey[key]; ok {
+			unmanaged = append(unmanaged, branchRestriction)
+			continue
+		}
+		existingByKey[key] = branchRestriction
+	}
+
+	for _, item := range d.Get("restriction").(*schema.Set).List() {
+		branchRestriction := newBranchRestrictionFromMap(item.(map[string]interface{}))
+		key := branchRestrictionKey(branchRestriction)
+
+		if current, ok := existingByKey[key]; ok {
+			delete(existingByKey, key)
+			err := putBranchRestriction(client, owner, repository, strconv.Itoa(current.ID), branchRestriction)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err := postBranchRestriction(client, owner, repository, branchRestriction, d.Timeout(timeout)); err != nil {
+			return err
+		}
+	}
+
+	for _, branchRestriction := range existingByKey {
+		unmanaged = append(unmanaged, branchRestriction)
+	}
+
+	for _, branchRestriction := range unmanaged {
+		err := deleteBranchRestriction(client, owner, repository, strconv.Itoa(branchRestriction.ID))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func resourceRepositoryBranchRestrictionsCreate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	if err := syncRepositoryBranchRestrictions(d, client, schema.TimeoutCreate); err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", d.Get("owner").(string), d.Get("repository").(string)))
+
+	return resourceRepositoryBranchRestrictionsRead(d, m)
+}
+
+func resourceRepositoryBranchRestrictionsRead(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	branchRestrictions, err := listBranchRestrictions(client,
+		d.Get("owner").(string),
+		d.Get("repository").(string),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	// Everything on the repository ends up in state, so anything that was not declared shows up in the plan
+	// as a restriction that is going to be removed.
+	restrictions := make([]interface{}, 0, len(branchRestrictions))
+	for _, branchRestriction := range branchRestrictions {
+		restrictions = append(restrictions, flattenBranchRestriction(branchRestriction))
+	}
+
+	d.Set("restriction", restrictions)
+
+	return nil
+}
+
+func resourceRepositoryBranchRestrictionsUpdate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	if err := syncRepositoryBranchRestrictions(d, client, schema.TimeoutUpdate); err != nil {
+		return err
+	}
+
+	return resourceRepositoryBranchRestrictionsRead(d, m)
+}
+
+func resourceRepositoryBranchRestrictionsDelete(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+	owner := d.Get("owner").(string)
+	repository := d.Get("repository").(string)
+
+	declared := make(map[string]bool)
+	for _, item := range d.Get("restriction").(*schema.Set).List() {
+		declared[branchRestrictionKey(newBranchRestrictionFromMap(item.(map[string]interface{})))] = true
+	}
+
+	branchRestrictions, err := listBranchRestrictions(client, owner, repository)
+	if err != nil {
+		return err
+	}
+
+	for _, branchRestriction := range branchRestrictions {
+		if !declared[branchRestrictionKey(&branchRestriction)] {
+			continue
+		}
+
+		if err := deleteBranchRestriction(client, owner, repository, strconv.Itoa(branchRestriction.ID)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func resourceRepositoryBranchRestrictionsImport(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	idparts := strings.Split(d.Id(), "/")
+	if len(idparts) != 2 {
+		return nil, fmt.Errorf("Incorrect ID format, should match `owner/repository`")
+	}
+
+	d.Set("owner", idparts[0])
+	d.Set("repository", idparts[1])
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,126 @@
+package bitbucket
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccBitbucketBranchRestrictions_basic(t *testing.T) {
+	testUser := os.Getenv("BITBUCKET_USERNAME")
+	testAccBitbucketBranchRestrictionsConfig := fmt.Sprintf(`
+		resource "bitbucket_repository" "test_repo" {
+			owner = "%s"
+			name = "test-repo-for-branch-restrictions-test"
+		}
+		resource "bitbucket_branch_restrictions" "test_repo" {
+			owner = "%s"
+			repository = "${bitbucket_repository.test_repo.name}"
+
+			restriction {
+				kind = "force"
+				pattern = "master"
+			}
+
+			restriction {
+				kind = "require_approvals_to_merge"
+				pattern = "master"
+				value = 2
+			}
+		}
+	`, testUser, testUser)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBitbucketBranchRestrictionsDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBitbucketBranchRestrictionsConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("bitbucket_branch_restrictions.test_repo", "restriction.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestBranchRestrictionRoundTrip(t *testing.T) {
+	branchRestriction := BranchRestriction{
+		ID:      12,
+		Kind:    "push",
+		Pattern: "master",
+		Users:   []User{{Username: "gob"}},
+		Groups:  []Group{{Owner: User{Username: "myteam"}, Slug: "developers"}},
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceRepositoryBranchRestrictions().Schema, map[string]interface{}{
+		"owner":      "myteam",
+		"repository": "terraform-code",
+	})
+
+	if err := d.Set("restriction", []interface{}{flattenBranchRestriction(branchRestriction)}); err != nil {
+		t.Fatalf("failed to set restriction: %s", err)
+	}
+
+	restrictions := d.Get("restriction").(*schema.Set).List()
+	if len(restrictions) != 1 {
+		t.Fatalf("expected 1 restriction, got %d", len(restrictions))
+	}
+
+	roundTripped := newBranchRestrictionFromMap(restrictions[0].(map[string]interface{}))
+
+	if branchRestrictionKey(roundTripped) != branchRestrictionKey(&branchRestriction) {
+		t.Errorf("expected key %s, got %s", branchRestrictionKey(&branchRestriction), branchRestrictionKey(roundTripped))
+	}
+
+	if roundTripped.BranchMatchKind != "glob" {
+		t.Errorf("expected branch_match_kind to default to glob, got %s", roundTripped.BranchMatchKind)
+	}
+
+	if len(roundTripped.Users) != 1 || roundTripped.Users[0].Username != "gob" {
+		t.Errorf("expected users to round trip, got %v", roundTripped.Users)
+	}
+
+	if len(roundTripped.Groups) != 1 || roundTripped.Groups[0].Slug != "developers" || roundTripped.Groups[0].Owner.Username != "myteam" {
+		t.Errorf("expected groups to round trip, got %v", roundTripped.Groups)
+	}
+}
+
+func testAccCheckBitbucketBranchRestrictionsDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*Client)
+	rs, ok := s.RootModule().Resources["bitbucket_branch_restrictions.test_repo"]
+	if !ok {
+		return fmt.Errorf("Not found %s", "bitbucket_branch_restrictions.test_repo")
+	}
+
+	branchRestrictions, err := listBranchRestrictions(client, rs.Primary.Attributes["owner"], rs.Primary.Attributes["repository"])
+	if err != nil {
+		// The repository is destroyed along with the restrictions.
+		return nil
+	}
+
+	if len(branchRestrictions) != 0 {
+		return fmt.Errorf("BranchRestrictions still exist")
+	}
+
+	return nil
+}
+
+func TestBranchRestrictionBlockSchema(t *testing.T) {
+	single := resourceBranchRestriction().Schema
+
+	for k := range branchRestrictionBlock().Schema {
+		if _, ok := single[k]; !ok {
+			t.Errorf("expected bitbucket_branch_restriction to have %s", k)
+		}
+	}
+
+	if len(single) != len(branchRestrictionBlock().Schema)+2 {
+		t.Errorf("expected bitbucket_branch_restriction to only add owner and repository")
+	}
+}
@@ -0,0 +1,204 @@
+package bitbucket
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// DeployKey is a read-only SSH key of a repository or project
+type DeployKey struct {
+	ID      int    `json:"id,omitempty"`
+	Key     string `json:"key"`
+	Label   string `json:"label"`
+	Comment string `json:"comment,omitempty"`
+}
+
+func resourceDeployKey() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceDeployKeyCreate,
+		Read:   resourceDeployKeyRead,
+		Update: resourceDeployKeyUpdate,
+		Delete: resourceDeployKeyDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceDeployKeyImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"workspace": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"repository": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"key_id": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"key": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validateSSHPublicKey,
+				StateFunc:    deployKeyStateFunc,
+			},
+			"label": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"comment": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// deployKeyStateFunc stores keys without their comment, bitbucket splits the comment off the key
+func deployKeyStateFunc(v interface{}) string {
+	return normalizeSSHPublicKey(v.(string))
+}
+
+func setDeployKey(d *schema.ResourceData, deployKey *DeployKey) {
+	d.Set("key_id", deployKey.ID)
+	d.Set("key", normalizeSSHPublicKey(deployKey.Key))
+	d.Set("label", deployKey.Label)
+	d.Set("comment", deployKey.Comment)
+}
+
+// decodeDeployKey reads the deploy key out of a response
+func decodeDeployKey(body []byte) (*DeployKey, error) {
+	var deployKey DeployKey
+
+	if err := json.Unmarshal(body, &deployKey); err != nil {
+		return nil, err
+	}
+
+	return &deployKey, nil
+}
+
+func deployKeysURL(d *schema.ResourceData) string {
+	return fmt.Sprintf("2.0/repositories/%s/%s/deploy-keys",
+		d.Get("workspace").(string),
+		d.Get("repository").(string),
+	)
+}
+
+func resourceDeployKeyCreate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	bytedata, err := json.Marshal(&DeployKey{
+		Key:   d.Get("key").(string),
+		Label: d.Get("label").(string),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	deployKeyReq, err := client.Post(deployKeysURL(d), bytes.NewBuffer(bytedata))
+	if err != nil {
+		return err
+	}
+
+	body, readerr := ioutil.ReadAll(deployKeyReq.Body)
+	if readerr != nil {
+		return readerr
+	}
+
+	deployKey, err := decodeDeployKey(body)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(strconv.Itoa(deployKey.ID))
+
+	return resourceDeployKeyRead(d, m)
+}
+
+func resourceDeployKeyRead(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	deployKeyReq, err := client.Get(fmt.Sprintf("%s/%s", deployKeysURL(d), d.Id()))
+
+	if deployKeyReq != nil && deployKeyReq.StatusCode == 404 {
+		d.SetId("")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	body, readerr := ioutil.ReadAll(deployKeyReq.Body)
+	if readerr != nil {
+		return readerr
+	}
+
+	deployKey, err := decodeDeployKey(body)
+	if err != nil {
+		return err
+	}
+
+	setDeployKey(d, deployKey)
+
+	return nil
+}
+
+func resourceDeployKeyUpdate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	bytedata, err := json.Marshal(&DeployKey{
+		Key:   d.Get("key").(string),
+		Label: d.Get("label").(string),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Put(fmt.Sprintf("%s/%s", deployKeysURL(d), d.Id()), bytes.NewBuffer(bytedata))
+	if err != nil {
+		return err
+	}
+
+	return resourceDeployKeyRead(d, m)
+}
+
+func resourceDeployKeyDelete(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	deployKeyReq, err := client.Delete(fmt.Sprintf("%s/%s", deployKeysURL(d), d.Id()))
+
+	if deployKeyReq != nil && deployKeyReq.StatusCode == 404 {
+		return nil
+	}
+
+	return err
+}
+
+func resourceDeployKeyImport(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	idparts := strings.Split(d.Id(), "/")
+	if len(idparts) != 3 {
+		return nil, fmt.Errorf("Incorrect ID format, should match `workspace/repository/id`")
+	}
+
+	if _, err := strconv.Atoi(idparts[2]); err != nil {
+		return nil, fmt.Errorf("Incorrect ID format, the id of a deploy key is a number, got %s", idparts[2])
+	}
+
+	d.Set("workspace", idparts[0])
+	d.Set("repository", idparts[1])
+	d.SetId(idparts[2])
+
+	return []*schema.ResourceData{d}, nil
+}
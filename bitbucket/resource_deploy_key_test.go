@@ -0,0 +1,86 @@
+package bitbucket
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccBitbucketDeployKey_basic(t *testing.T) {
+	testUser := os.Getenv("BITBUCKET_USERNAME")
+	testAccBitbucketDeployKeyConfig := func(label string) string {
+		return fmt.Sprintf(`
+			resource "bitbucket_repository" "test_repo" {
+				owner = "%s"
+				name = "test-repo-for-deploy-key-test"
+			}
+
+			resource "bitbucket_deploy_key" "test" {
+				workspace = bitbucket_repository.test_repo.owner
+				repository = bitbucket_repository.test_repo.name
+				key = "  %s ci@example.com\n"
+				label = "%s"
+			}
+		`, testUser, testSSHPublicKey, label)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBitbucketDeployKeyDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBitbucketDeployKeyConfig("ci"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("bitbucket_deploy_key.test", "key", normalizeSSHPublicKey(testSSHPublicKey)),
+					resource.TestCheckResourceAttr("bitbucket_deploy_key.test", "label", "ci"),
+					resource.TestCheckResourceAttrSet("bitbucket_deploy_key.test", "key_id"),
+				),
+			},
+			{
+				Config: testAccBitbucketDeployKeyConfig("ci-renamed"),
+				Check:  resource.TestCheckResourceAttr("bitbucket_deploy_key.test", "label", "ci-renamed"),
+			},
+			{
+				ResourceName:      "bitbucket_deploy_key.test",
+				ImportState:       true,
+				ImportStateIdFunc: testAccBitbucketDeployKeyImportId,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccBitbucketDeployKeyImportId(s *terraform.State) (string, error) {
+	rs, ok := s.RootModule().Resources["bitbucket_deploy_key.test"]
+	if !ok {
+		return "", fmt.Errorf("Not found %s", "bitbucket_deploy_key.test")
+	}
+
+	return fmt.Sprintf("%s/%s/%s", rs.Primary.Attributes["workspace"], rs.Primary.Attributes["repository"], rs.Primary.ID), nil
+}
+
+func testAccCheckBitbucketDeployKeyDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*Client)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "bitbucket_deploy_key" {
+			continue
+		}
+
+		response, _ := client.Get(fmt.Sprintf("2.0/repositories/%s/%s/deploy-keys/%s",
+			rs.Primary.Attributes["workspace"],
+			rs.Primary.Attributes["repository"],
+			rs.Primary.ID,
+		))
+
+		if response != nil && response.StatusCode != 404 {
+			return fmt.Errorf("Deploy key still exists")
+		}
+	}
+
+	return nil
+}
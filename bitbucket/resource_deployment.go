@@ -9,25 +9,76 @@ import (
 	"io/ioutil"
 	"log"
 	"net/url"
+	"strings"
 )
 
 // Deployment structure for handling key info
 type Deployment struct {
-	Name  string `json:"name"`
-	Stage *Stage `json:"environment_type"`
-	UUID  string `json:"uuid,omitempty"`
+	Name                   string                  `json:"name"`
+	Stage                  *Stage                  `json:"environment_type"`
+	UUID                   string                  `json:"uuid,omitempty"`
+	Restrictions           *DeploymentRestrictions `json:"restrictions,omitempty"`
+	Lock                   *DeploymentLock         `json:"lock,omitempty"`
+	EnvironmentLockEnabled *bool                   `json:"environment_lock_enabled,omitempty"`
+	Rank                   *int                    `json:"rank,omitempty"`
+	Hidden                 bool                    `json:"hidden"`
 }
 
 type Stage struct {
 	Name string `json:"name"`
 }
 
+// DeploymentRestrictions limit who can deploy to an environment and from which branches
+type DeploymentRestrictions struct {
+	AdminOnly          bool                          `json:"admin_only"`
+	BranchRestrictions []DeploymentBranchRestriction `json:"branch_restrictions"`
+}
+
+// DeploymentBranchRestriction is a glob of branches that are allowed to deploy to an environment
+type DeploymentBranchRestriction struct {
+	Pattern string `json:"pattern"`
+}
+
+// DeploymentLock is the current lock of an environment, bitbucket takes it while a deployment is running
+type DeploymentLock struct {
+	Name string `json:"name,omitempty"`
+}
+
+// DeploymentChange is what we send to the changes endpoint to update an environment in place
+type DeploymentChange struct {
+	Change DeploymentChangeFields `json:"change"`
+}
+
+// DeploymentChangeFields are the fields of an environment that can be changed, anything left empty is kept
+type DeploymentChangeFields struct {
+	Name                   string                  `json:"name,omitempty"`
+	Restrictions           *DeploymentRestrictions `json:"restrictions,omitempty"`
+	EnvironmentLockEnabled *bool                   `json:"environment_lock_enabled,omitempty"`
+	Rank                   *int                    `json:"rank,omitempty"`
+	Hidden                 *bool                   `json:"hidden,omitempty"`
+}
+
+// PaginatedDeployments is a paginated list that the bitbucket api returns
+type PaginatedDeployments struct {
+	Values []Deployment `json:"values,omitempty"`
+	Page   int          `json:"page,omitempty"`
+	Size   int          `json:"size,omitempty"`
+	Next   string       `json:"next,omitempty"`
+}
+
 func resourceDeployment() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceDeploymentCreate,
 		Update: resourceDeploymentUpdate,
 		Read:   resourceDeploymentRead,
 		Delete: resourceDeploymentDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceDeploymentImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultVisibilityTimeout),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"uuid": {
@@ -41,6 +92,8 @@ func resourceDeployment() *schema.Resource {
 			"stage": {
 				Type:     schema.TypeString,
 				Required: true,
+				// Bitbucket can not move an environment to another stage, it has to be recreated.
+				ForceNew: true,
 				ValidateFunc: validation.StringInSlice([]string{
 					"Test",
 					"Staging",
@@ -51,6 +104,57 @@ func resourceDeployment() *schema.Resource {
 			"repository": {
 				Type:     schema.TypeString,
 				Required: true,
+				ForceNew: true,
+			},
+			"rank": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"hidden": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"restrictions": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"admin_only": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"branch_patterns": {
+							Type:     schema.TypeList,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+							Optional: true,
+						},
+					},
+				},
+			},
+			"lock": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"enabled": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+						"status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
 			},
 		},
 	}
@@ -62,10 +166,80 @@ func newDeploymentFromResource(d *schema.ResourceData) *Deployment {
 		Stage: &Stage{
 			Name: d.Get("stage").(string),
 		},
+		Restrictions:           expandDeploymentRestrictions(d.Get("restrictions").([]interface{})),
+		EnvironmentLockEnabled: expandDeploymentLockEnabled(d.Get("lock").([]interface{})),
+		Hidden:                 d.Get("hidden").(bool),
 	}
+
+	// Without a rank bitbucket puts the environment at the end of its stage.
+	if v, ok := d.GetOkExists("rank"); ok {
+		rank := v.(int)
+		dk.Rank = &rank
+	}
+
 	return dk
 }
 
+// expandDeploymentRestrictions turns the restrictions block into the payload, nil leaves bitbucket's defaults
+func expandDeploymentRestrictions(l []interface{}) *DeploymentRestrictions {
+	if len(l) == 0 || l[0] == nil {
+		return nil
+	}
+
+	m := l[0].(map[string]interface{})
+	restrictions := &DeploymentRestrictions{
+		AdminOnly:          m["admin_only"].(bool),
+		BranchRestrictions: make([]DeploymentBranchRestriction, 0),
+	}
+
+	for _, pattern := range m["branch_patterns"].([]interface{}) {
+		restrictions.BranchRestrictions = append(restrictions.BranchRestrictions, DeploymentBranchRestriction{Pattern: pattern.(string)})
+	}
+
+	return restrictions
+}
+
+func flattenDeploymentRestrictions(restrictions *DeploymentRestrictions) []interface{} {
+	if restrictions == nil {
+		return nil
+	}
+
+	patterns := make([]interface{}, 0, len(restrictions.BranchRestrictions))
+	for _, branchRestriction := range restrictions.BranchRestrictions {
+		patterns = append(patterns, branchRestriction.Pattern)
+	}
+
+	return []interface{}{map[string]interface{}{
+		"admin_only":      restrictions.AdminOnly,
+		"branch_patterns": patterns,
+	}}
+}
+
+func expandDeploymentLockEnabled(l []interface{}) *bool {
+	if len(l) == 0 || l[0] == nil {
+		return nil
+	}
+
+	enabled := l[0].(map[string]interface{})["enabled"].(bool)
+	return &enabled
+}
+
+func flattenDeploymentLock(deployment *Deployment) []interface{} {
+	if deployment.EnvironmentLockEnabled == nil {
+		return nil
+	}
+
+	status := ""
+	if deployment.Lock != nil {
+		status = deployment.Lock.Name
+	}
+
+	return []interface{}{map[string]interface{}{
+		"enabled": *deployment.EnvironmentLockEnabled,
+		"status":  status,
+	}}
+}
+
 func resourceDeploymentCreate(d *schema.ResourceData, m interface{}) error {
 
 	client := m.(*Client)
@@ -97,39 +271,98 @@ func resourceDeploymentCreate(d *schema.ResourceData, m interface{}) error {
 	d.Set("uuid", deployment.UUID)
 	d.SetId(fmt.Sprintf("%s:%s", d.Get("repository"), deployment.UUID))
 
+	err = waitUntilVisible(fmt.Sprintf("deployment environment %s", deployment.Name), d.Timeout(schema.TimeoutCreate), func() (bool, error) {
+		req, err := client.Get(fmt.Sprintf("2.0/repositories/%s/environments/%s",
+			d.Get("repository").(string),
+			deployment.UUID,
+		))
+
+		if req != nil && req.StatusCode == 404 {
+			return false, nil
+		}
+
+		return err == nil, err
+	})
+
+	if err != nil {
+		return err
+	}
+
 	return resourceDeploymentRead(d, m)
 }
 
+// listDeployments fetches every page of deployment environments of the repository
+func listDeployments(client *Client, repository string) ([]Deployment, error) {
+	var deployments []Deployment
+
+	resourceURL := fmt.Sprintf("2.0/repositories/%s/environments/", repository)
+
+	for {
+		req, err := client.Get(resourceURL)
+		if err != nil {
+			return nil, err
+		}
+
+		var page PaginatedDeployments
+		decoder := json.NewDecoder(req.Body)
+		err = decoder.Decode(&page)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		deployments = append(deployments, page.Values...)
+
+		if page.Next == "" {
+			break
+		}
+
+		resourceURL = nextPageEndpoint(page.Next)
+	}
+
+	return deployments, nil
+}
+
 func resourceDeploymentRead(d *schema.ResourceData, m interface{}) error {
 
 	client := m.(*Client)
-	req, _ := client.Get(fmt.Sprintf("2.0/repositories/%s/environments/%s",
+	req, err := client.Get(fmt.Sprintf("2.0/repositories/%s/environments/%s",
 		d.Get("repository").(string),
 		d.Get("uuid").(string),
 	))
 
 	log.Printf("ID: %s", url.PathEscape(d.Id()))
 
-	if req.StatusCode == 200 {
-		var Deployment Deployment
-		body, readerr := ioutil.ReadAll(req.Body)
-		if readerr != nil {
-			return readerr
-		}
+	if req != nil && req.StatusCode == 404 {
+		d.SetId("")
+		return nil
+	}
 
-		decodeerr := json.Unmarshal(body, &Deployment)
-		if decodeerr != nil {
-			return decodeerr
-		}
+	if err != nil {
+		return err
+	}
+
+	var Deployment Deployment
+	body, readerr := ioutil.ReadAll(req.Body)
+	if readerr != nil {
+		return readerr
+	}
 
-		d.Set("uuid", Deployment.UUID)
-		d.Set("name", Deployment.Name)
-		d.Set("stage", Deployment.Stage.Name)
+	decodeerr := json.Unmarshal(body, &Deployment)
+	if decodeerr != nil {
+		return decodeerr
 	}
 
-	if req.StatusCode == 404 {
-		d.SetId("")
-		return nil
+	d.Set("uuid", Deployment.UUID)
+	d.Set("name", Deployment.Name)
+	d.Set("stage", Deployment.Stage.Name)
+	d.Set("hidden", Deployment.Hidden)
+	d.Set("restrictions", flattenDeploymentRestrictions(Deployment.Restrictions))
+	d.Set("lock", flattenDeploymentLock(&Deployment))
+
+	// Reordering the environments in the UI changes their rank, which shows up as drift.
+	if Deployment.Rank != nil {
+		d.Set("rank", *Deployment.Rank)
 	}
 
 	return nil
@@ -137,23 +370,45 @@ func resourceDeploymentRead(d *schema.ResourceData, m interface{}) error {
 
 func resourceDeploymentUpdate(d *schema.ResourceData, m interface{}) error {
 	client := m.(*Client)
-	rvcr := newDeploymentFromResource(d)
-	bytedata, err := json.Marshal(rvcr)
 
-	if err != nil {
-		return err
-	}
-	req, err := client.Put(fmt.Sprintf("2.0/repositories/%s/environments/%s",
-		d.Get("repository").(string),
-		d.Get("uuid").(string),
-	), bytes.NewBuffer(bytedata))
+	if d.HasChange("name") || d.HasChange("restrictions") || d.HasChange("lock") || d.HasChange("rank") || d.HasChange("hidden") {
+		change := &DeploymentChange{}
 
-	if err != nil {
-		return err
-	}
+		if d.HasChange("name") {
+			change.Change.Name = d.Get("name").(string)
+		}
 
-	if req.StatusCode != 200 {
-		return nil
+		if d.HasChange("restrictions") {
+			change.Change.Restrictions = expandDeploymentRestrictions(d.Get("restrictions").([]interface{}))
+		}
+
+		if d.HasChange("lock") {
+			change.Change.EnvironmentLockEnabled = expandDeploymentLockEnabled(d.Get("lock").([]interface{}))
+		}
+
+		if d.HasChange("rank") {
+			rank := d.Get("rank").(int)
+			change.Change.Rank = &rank
+		}
+
+		if d.HasChange("hidden") {
+			hidden := d.Get("hidden").(bool)
+			change.Change.Hidden = &hidden
+		}
+
+		bytedata, err := json.Marshal(change)
+		if err != nil {
+			return err
+		}
+
+		_, err = client.Post(fmt.Sprintf("2.0/repositories/%s/environments/%s/changes/",
+			d.Get("repository").(string),
+			d.Get("uuid").(string),
+		), bytes.NewBuffer(bytedata))
+
+		if err != nil {
+			return err
+		}
 	}
 
 	return resourceDeploymentRead(d, m)
@@ -167,3 +422,52 @@ func resourceDeploymentDelete(d *schema.ResourceData, m interface{}) error {
 	))
 	return err
 }
+
+// parseDeploymentImportId splits an import ID into the repository and the environment. The environment is either
+// a UUID in braces or a name. Besides `owner/repo/env` the `owner/repo:{uuid}` IDs we store are accepted as well.
+func parseDeploymentImportId(id string) (repository string, environment string, err error) {
+	if strings.Contains(id, ":") {
+		repository, environment = parseDeploymentId(id)
+		return repository, environment, nil
+	}
+
+	parts := strings.SplitN(id, "/", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", fmt.Errorf("Incorrect ID format, should match `owner/repo/env-name` or `owner/repo/{uuid}`")
+	}
+
+	return parts[0] + "/" + parts[1], parts[2], nil
+}
+
+func resourceDeploymentImport(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	repository, environment, err := parseDeploymentImportId(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	uuid := environment
+	if !strings.HasPrefix(environment, "{") {
+		deployments, err := listDeployments(m.(*Client), repository)
+		if err != nil {
+			return nil, err
+		}
+
+		uuid = ""
+		for _, deployment := range deployments {
+			if deployment.Name == environment {
+				uuid = deployment.UUID
+				break
+			}
+		}
+
+		if uuid == "" {
+			return nil, fmt.Errorf("no deployment environment named %s in %s", environment, repository)
+		}
+	}
+
+	d.Set("repository", repository)
+	d.Set("uuid", uuid)
+	d.SetId(fmt.Sprintf("%s:%s", repository, uuid))
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -3,6 +3,7 @@ package bitbucket
 import (
 	"fmt"
 	"os"
+	"reflect"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
@@ -68,3 +69,173 @@ func testAccCheckBitbucketDeploymentExists(n string, deployment *Deployment) res
 		return nil
 	}
 }
+
+func TestAccBitbucketDeployment_renameAndImport(t *testing.T) {
+	testUser := os.Getenv("BITBUCKET_USERNAME")
+	testAccBitbucketDeploymentConfig := func(name string) string {
+		return fmt.Sprintf(`
+			resource "bitbucket_repository" "test_repo" {
+				owner = "%s"
+				name = "test-repo-for-deployment-test"
+			}
+			resource "bitbucket_deployment" "test_deploy" {
+				name = "%s"
+				stage = "Staging"
+				repository = bitbucket_repository.test_repo.id
+			}
+		`, testUser, name)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBitbucketDeploymentConfig("test_deploy"),
+				Check:  resource.TestCheckResourceAttr("bitbucket_deployment.test_deploy", "name", "test_deploy"),
+			},
+			{
+				Config: testAccBitbucketDeploymentConfig("test_deploy_renamed"),
+				Check:  resource.TestCheckResourceAttr("bitbucket_deployment.test_deploy", "name", "test_deploy_renamed"),
+			},
+			{
+				ResourceName:      "bitbucket_deployment.test_deploy",
+				ImportState:       true,
+				ImportStateId:     fmt.Sprintf("%s/test-repo-for-deployment-test/test_deploy_renamed", testUser),
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestParseDeploymentImportId(t *testing.T) {
+	cases := map[string][2]string{
+		"gob/illusions/test":         {"gob/illusions", "test"},
+		"gob/illusions/{abc-123}":    {"gob/illusions", "{abc-123}"},
+		"gob/illusions:{abc-123}":    {"gob/illusions", "{abc-123}"},
+		"gob/illusions/with/a/slash": {"gob/illusions", "with/a/slash"},
+	}
+
+	for id, expected := range cases {
+		repository, environment, err := parseDeploymentImportId(id)
+		if err != nil {
+			t.Errorf("expected %s to parse, got %s", id, err)
+			continue
+		}
+
+		if repository != expected[0] || environment != expected[1] {
+			t.Errorf("expected %s to parse to %v, got %s and %s", id, expected, repository, environment)
+		}
+	}
+
+	for _, id := range []string{"gob", "gob/illusions", "gob//test"} {
+		if _, _, err := parseDeploymentImportId(id); err == nil {
+			t.Errorf("expected %s not to parse", id)
+		}
+	}
+}
+
+func TestDeploymentRestrictionsRoundTrip(t *testing.T) {
+	config := []interface{}{map[string]interface{}{
+		"admin_only":      true,
+		"branch_patterns": []interface{}{"main", "release/*"},
+	}}
+
+	restrictions := expandDeploymentRestrictions(config)
+	if !restrictions.AdminOnly || len(restrictions.BranchRestrictions) != 2 || restrictions.BranchRestrictions[1].Pattern != "release/*" {
+		t.Fatalf("unexpected restrictions %+v", restrictions)
+	}
+
+	if !reflect.DeepEqual(flattenDeploymentRestrictions(restrictions), config) {
+		t.Errorf("expected %v, got %v", config, flattenDeploymentRestrictions(restrictions))
+	}
+
+	if expandDeploymentRestrictions(nil) != nil {
+		t.Errorf("expected no restrictions without a block")
+	}
+}
+
+func TestAccBitbucketDeployment_restrictions(t *testing.T) {
+	testUser := os.Getenv("BITBUCKET_USERNAME")
+	testAccBitbucketDeploymentConfig := func(adminOnly bool) string {
+		return fmt.Sprintf(`
+			resource "bitbucket_repository" "test_repo" {
+				owner = "%s"
+				name = "test-repo-for-deployment-test"
+			}
+			resource "bitbucket_deployment" "test_deploy" {
+				name = "production"
+				stage = "Production"
+				repository = bitbucket_repository.test_repo.id
+
+				restrictions {
+					admin_only = %t
+					branch_patterns = ["master", "release/*"]
+				}
+
+				lock {
+					enabled = true
+				}
+			}
+		`, testUser, adminOnly)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBitbucketDeploymentConfig(true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("bitbucket_deployment.test_deploy", "restrictions.0.admin_only", "true"),
+					resource.TestCheckResourceAttr("bitbucket_deployment.test_deploy", "restrictions.0.branch_patterns.#", "2"),
+					resource.TestCheckResourceAttr("bitbucket_deployment.test_deploy", "lock.0.enabled", "true"),
+				),
+			},
+			{
+				Config: testAccBitbucketDeploymentConfig(false),
+				Check:  resource.TestCheckResourceAttr("bitbucket_deployment.test_deploy", "restrictions.0.admin_only", "false"),
+			},
+		},
+	})
+}
+
+func TestAccBitbucketDeployment_rankAndHidden(t *testing.T) {
+	testUser := os.Getenv("BITBUCKET_USERNAME")
+	testAccBitbucketDeploymentConfig := func(rank int, hidden bool) string {
+		return fmt.Sprintf(`
+			resource "bitbucket_repository" "test_repo" {
+				owner = "%s"
+				name = "test-repo-for-deployment-test"
+			}
+			resource "bitbucket_deployment" "test_deploy" {
+				name = "retired"
+				stage = "Test"
+				repository = bitbucket_repository.test_repo.id
+				rank = %d
+				hidden = %t
+			}
+		`, testUser, rank, hidden)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBitbucketDeploymentConfig(0, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("bitbucket_deployment.test_deploy", "rank", "0"),
+					resource.TestCheckResourceAttr("bitbucket_deployment.test_deploy", "hidden", "false"),
+				),
+			},
+			{
+				Config: testAccBitbucketDeploymentConfig(1, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("bitbucket_deployment.test_deploy", "rank", "1"),
+					resource.TestCheckResourceAttr("bitbucket_deployment.test_deploy", "hidden", "true"),
+				),
+			},
+		},
+	})
+}
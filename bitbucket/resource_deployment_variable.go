@@ -4,12 +4,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"github.com/hashicorp/terraform/helper/customdiff"
 	"github.com/hashicorp/terraform/helper/schema"
 	"io/ioutil"
 	"log"
 	"net/url"
 	"strings"
-	"time"
 )
 
 // DeploymentVariable structure for handling key info
@@ -35,6 +35,15 @@ func resourceDeploymentVariable() *schema.Resource {
 		Read:   resourceDeploymentVariableRead,
 		Delete: resourceDeploymentVariableDelete,
 
+		CustomizeDiff: customdiff.All(
+			resourceVariableKeyDiff,
+			resourceVariableValueHashDiff,
+		),
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultVisibilityTimeout),
+		},
+
 		Schema: map[string]*schema.Schema{
 			"uuid": {
 				Type:     schema.TypeString,
@@ -45,8 +54,19 @@ func resourceDeploymentVariable() *schema.Resource {
 				Required: true,
 			},
 			"value": {
-				Type:     schema.TypeString,
-				Required: true,
+				Type:      schema.TypeString,
+				Required:  true,
+				Sensitive: true,
+			},
+			"value_hash": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"value_hash_salt": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
 			},
 			"secured": {
 				Type:     schema.TypeBool,
@@ -108,57 +128,46 @@ func resourceDeploymentVariableCreate(d *schema.ResourceData, m interface{}) err
 	d.Set("uuid", rv.UUID)
 	d.SetId(rv.UUID)
 
-	time.Sleep(5000 * time.Millisecond) // sleep for a while, to allow BitBucket cache to catch up
+	if err := setVariableValueHash(d, rvcr.Value); err != nil {
+		return err
+	}
+
+	// The variables listing is cached, the new variable can take a while to show up in it.
+	err = waitUntilVisible(fmt.Sprintf("deployment variable %s", rv.Key), d.Timeout(schema.TimeoutCreate), func() (bool, error) {
+		variable, err := findPipelineVariableByUUID(client, d.Get("deployment").(string), rv.UUID)
+		return variable != nil, err
+	})
+
+	if err != nil {
+		return err
+	}
+
 	return resourceDeploymentVariableRead(d, m)
 }
 
 func resourceDeploymentVariableRead(d *schema.ResourceData, m interface{}) error {
 
-	repository, deployment := parseDeploymentId(d.Get("deployment").(string))
 	client := m.(*Client)
-	rvReq, _ := client.Get(fmt.Sprintf("2.0/repositories/%s/deployments_config/environments/%s/variables",
-		repository,
-		deployment,
-	))
 
 	log.Printf("ID: %s", url.PathEscape(d.Id()))
 
-	if rvReq.StatusCode == 200 {
-		var prv PaginatedDeploymentVariables
-		body, readerr := ioutil.ReadAll(rvReq.Body)
-		if readerr != nil {
-			return readerr
-		}
-
-		decodeerr := json.Unmarshal(body, &prv)
-		if decodeerr != nil {
-			return decodeerr
-		}
-
-		if prv.Size < 1 {
-			d.SetId("")
-			return nil
-		}
-
-		var uuid = d.Get("uuid").(string)
-		for _, rv := range prv.Values {
-			if rv.UUID == uuid {
-				d.SetId(rv.UUID)
-				d.Set("key", rv.Key)
-				d.Set("value", rv.Value)
-				d.Set("secured", rv.Secured)
-				return nil
-			}
-		}
-		d.SetId("")
+	// Deployment variables can not be fetched one by one, they are looked up in the listing of the environment.
+	rv, err := readPipelineVariable(d, client, d.Get("deployment").(string), findPipelineVariableByUUID)
+	if err != nil {
+		return err
 	}
 
-	if rvReq.StatusCode == 404 {
+	if rv == nil {
+		log.Printf("[WARN] Deployment variable %s not found, removing from state", d.Id())
 		d.SetId("")
 		return nil
 	}
 
-	return nil
+	d.SetId(rv.UUID)
+	d.Set("uuid", rv.UUID)
+	d.Set("key", rv.Key)
+
+	return setVariableValue(d, rv.Secured, rv.Value)
 }
 
 func resourceDeploymentVariableUpdate(d *schema.ResourceData, m interface{}) error {
@@ -170,7 +179,7 @@ func resourceDeploymentVariableUpdate(d *schema.ResourceData, m interface{}) err
 		return err
 	}
 	repository, deployment := parseDeploymentId(d.Get("deployment").(string))
-	req, err := client.Put(fmt.Sprintf("2.0/repositories/%s/deployments_config/environments/%s/variables/%s",
+	_, err = client.Put(fmt.Sprintf("2.0/repositories/%s/deployments_config/environments/%s/variables/%s",
 		repository,
 		deployment,
 		d.Get("uuid").(string),
@@ -180,8 +189,8 @@ func resourceDeploymentVariableUpdate(d *schema.ResourceData, m interface{}) err
 		return err
 	}
 
-	if req.StatusCode != 200 {
-		return nil
+	if err := setVariableValueHash(d, rvcr.Value); err != nil {
+		return err
 	}
 
 	return resourceDeploymentVariableRead(d, m)
@@ -2,6 +2,9 @@ package bitbucket
 
 import (
 	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -19,16 +22,41 @@ type Hook struct {
 	Active               bool     `json:"active"`
 	SkipCertVerification bool     `json:"skip_cert_verification"`
 	Events               []string `json:"events,omitempty"`
+	Secret               *string  `json:"secret,omitempty"`
+	SecretSet            *bool    `json:"secret_set,omitempty"`
 }
 
+// PaginatedHooks is a paginated list that the bitbucket api returns
+type PaginatedHooks struct {
+	Values []Hook `json:"values,omitempty"`
+	Page   int    `json:"page,omitempty"`
+	Size   int    `json:"size,omitempty"`
+	Next   string `json:"next,omitempty"`
+}
+
+// secretSetOutsideTerraform is stored as the secret hash when bitbucket has a secret we did not set, so the
+// next plan removes it or replaces it with the configured one.
+const secretSetOutsideTerraform = "set outside of terraform"
+
 func resourceHook() *schema.Resource {
-	return &schema.Resource{
+	resource := &schema.Resource{
 		Create: resourceHookCreate,
 		Read:   resourceHookRead,
 		Update: resourceHookUpdate,
 		Delete: resourceHookDelete,
 		Exists: resourceHookExists,
 
+		CustomizeDiff: resourceHookSecretDiff,
+
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Type:    resourceHookV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceHookStateUpgradeV0,
+				Version: 0,
+			},
+		},
+
 		Schema: map[string]*schema.Schema{
 			"owner": {
 				Type:     schema.TypeString,
@@ -40,10 +68,89 @@ func resourceHook() *schema.Resource {
 				Required: true,
 				ForceNew: true,
 			},
+		},
+	}
+
+	for k, v := range hookSchema("repository") {
+		resource.Schema[k] = v
+	}
+
+	return resource
+}
+
+// hookSchema is the part of the schema that repository and workspace hooks share
+func hookSchema(subjectType string) map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"active": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  true,
+		},
+		"url": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"uuid": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"description": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"events": {
+			Type:     schema.TypeSet,
+			Required: true,
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validateHookEvent(subjectType),
+			},
+			Set: schema.HashString,
+		},
+		"skip_cert_verification": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		"secret": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			Sensitive:     true,
+			ConflictsWith: []string{"generate_secret"},
+		},
+		"generate_secret": {
+			Type:          schema.TypeBool,
+			Optional:      true,
+			Default:       false,
+			ConflictsWith: []string{"secret"},
+		},
+		"generated_secret": {
+			Type:      schema.TypeString,
+			Computed:  true,
+			Sensitive: true,
+		},
+		"secret_hash": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+	}
+}
+
+// resourceHookV0 is the schema before secrets were added, back when skip_cert_verification defaulted to true
+func resourceHookV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"owner": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"repository": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
 			"active": {
 				Type:     schema.TypeBool,
 				Optional: true,
-				Default:  true,
 			},
 			"url": {
 				Type:     schema.TypeString,
@@ -66,12 +173,97 @@ func resourceHook() *schema.Resource {
 			"skip_cert_verification": {
 				Type:     schema.TypeBool,
 				Optional: true,
-				Default:  true,
 			},
 		},
 	}
 }
 
+// resourceHookStateUpgradeV0 keeps the recorded skip_cert_verification of existing hooks, so the switch to
+// verifying certificates by default shows up in the plan instead of happening silently.
+func resourceHookStateUpgradeV0(rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+	if _, ok := rawState["skip_cert_verification"]; !ok {
+		rawState["skip_cert_verification"] = true
+	}
+
+	rawState["generate_secret"] = false
+	rawState["secret_hash"] = ""
+
+	return rawState, nil
+}
+
+// generateHookSecret returns a random secret for signing hook deliveries
+func generateHookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// hashHookSecret is what we keep to notice secret changes, bitbucket never hands the secret back
+func hashHookSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// hookSecret returns the secret the hook should have, either the configured or the generated one
+func hookSecret(d *schema.ResourceData) (string, error) {
+	if secret := d.Get("secret").(string); secret != "" {
+		d.Set("generated_secret", "")
+		return secret, nil
+	}
+
+	if !d.Get("generate_secret").(bool) {
+		d.Set("generated_secret", "")
+		return "", nil
+	}
+
+	// The planned value is unknown while a secret still has to be generated, the state has the existing one.
+	if generated, _ := d.GetChange("generated_secret"); generated.(string) != "" {
+		d.Set("generated_secret", generated)
+		return generated.(string), nil
+	}
+
+	generated, err := generateHookSecret()
+	if err != nil {
+		return "", err
+	}
+
+	d.Set("generated_secret", generated)
+	return generated, nil
+}
+
+func resourceHookSecretDiff(d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("secret") || !d.NewValueKnown("generate_secret") {
+		return d.SetNewComputed("secret_hash")
+	}
+
+	secret := d.Get("secret").(string)
+
+	if secret == "" && d.Get("generate_secret").(bool) {
+		secret = d.Get("generated_secret").(string)
+		if secret == "" {
+			if err := d.SetNewComputed("generated_secret"); err != nil {
+				return err
+			}
+			return d.SetNewComputed("secret_hash")
+		}
+	} else if d.Get("generated_secret").(string) != "" {
+		if err := d.SetNew("generated_secret", ""); err != nil {
+			return err
+		}
+	}
+
+	if hash := hashHookSecret(secret); d.Get("secret_hash").(string) != hash {
+		return d.SetNew("secret_hash", hash)
+	}
+
+	return nil
+}
+
 func createHook(d *schema.ResourceData) *Hook {
 
 	events := make([]string, 0, len(d.Get("events").(*schema.Set).List()))
@@ -89,20 +281,37 @@ func createHook(d *schema.ResourceData) *Hook {
 	}
 }
 
-func resourceHookCreate(d *schema.ResourceData, m interface{}) error {
-	client := m.(*Client)
+// saveHook creates the hook at the hooks endpoint when it is new and updates it at its own endpoint otherwise, the
+// same for repository and workspace hooks
+func saveHook(d *schema.ResourceData, client *Client, endpoint string) error {
 	hook := createHook(d)
 
+	secret, err := hookSecret(d)
+	if err != nil {
+		return err
+	}
+
+	// Leaving the secret out keeps the one bitbucket has, an empty one removes it.
+	if (d.Id() == "" && secret != "") || (d.Id() != "" && d.HasChange("secret_hash")) {
+		hook.Secret = &secret
+	}
+
 	payload, err := json.Marshal(hook)
 	if err != nil {
 		return err
 	}
 
-	hookReq, err := client.Post(fmt.Sprintf("2.0/repositories/%s/%s/hooks",
-		d.Get("owner").(string),
-		d.Get("repository").(string),
-	), bytes.NewBuffer(payload))
+	if d.Id() != "" {
+		_, err = client.Put(endpoint, bytes.NewBuffer(payload))
+		if err != nil {
+			return err
+		}
 
+		d.Set("secret_hash", hashHookSecret(secret))
+		return nil
+	}
+
+	hookReq, err := client.Post(endpoint, bytes.NewBuffer(payload))
 	if err != nil {
 		return err
 	}
@@ -118,9 +327,26 @@ func resourceHookCreate(d *schema.ResourceData, m interface{}) error {
 	}
 
 	d.SetId(hook.UUID)
+	d.Set("secret_hash", hashHookSecret(secret))
+
+	return nil
+}
+
+func resourceHookCreate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	err := saveHook(d, client, fmt.Sprintf("2.0/repositories/%s/%s/hooks",
+		d.Get("owner").(string),
+		d.Get("repository").(string),
+	))
+
+	if err != nil {
+		return err
+	}
 
 	return resourceHookRead(d, m)
 }
+
 func resourceHookRead(d *schema.ResourceData, m interface{}) error {
 	client := m.(*Client)
 
@@ -149,37 +375,77 @@ func resourceHookRead(d *schema.ResourceData, m interface{}) error {
 			return decodeerr
 		}
 
-		d.Set("uuid", hook.UUID)
-		d.Set("description", hook.Description)
-		d.Set("active", hook.Active)
-		d.Set("url", hook.URL)
-		d.Set("skip_cert_verification", hook.SkipCertVerification)
+		setHookFields(d, &hook)
+	}
+
+	return nil
+}
+
+// listHooks fetches every page of hooks on the repository
+func listHooks(client *Client, owner, repository string) ([]Hook, error) {
+	var hooks []Hook
+
+	resourceURL := fmt.Sprintf("2.0/repositories/%s/%s/hooks", owner, repository)
+
+	for {
+		hooksReq, err := client.Get(resourceURL)
+		if err != nil {
+			return nil, err
+		}
+
+		var page PaginatedHooks
+		decoder := json.NewDecoder(hooksReq.Body)
+		err = decoder.Decode(&page)
+		hooksReq.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		hooks = append(hooks, page.Values...)
+
+		if page.Next == "" {
+			break
+		}
+
+		resourceURL = nextPageEndpoint(page.Next)
+	}
 
-		eventsList := make([]string, 0, len(hook.Events))
+	return hooks, nil
+}
 
-		for _, event := range hook.Events {
-			eventsList = append(eventsList, event)
+// setHookFields copies what bitbucket has for a hook into the state
+func setHookFields(d *schema.ResourceData, hook *Hook) {
+	d.Set("uuid", hook.UUID)
+	d.Set("description", hook.Description)
+	d.Set("active", hook.Active)
+	d.Set("url", hook.URL)
+	d.Set("skip_cert_verification", hook.SkipCertVerification)
+
+	if hook.SecretSet != nil {
+		if !*hook.SecretSet {
+			d.Set("secret_hash", "")
+		} else if d.Get("secret_hash").(string) == "" {
+			d.Set("secret_hash", secretSetOutsideTerraform)
 		}
+	}
 
-		d.Set("events", eventsList)
+	eventsList := make([]string, 0, len(hook.Events))
+
+	for _, event := range hook.Events {
+		eventsList = append(eventsList, event)
 	}
 
-	return nil
+	d.Set("events", eventsList)
 }
 
 func resourceHookUpdate(d *schema.ResourceData, m interface{}) error {
 	client := m.(*Client)
-	hook := createHook(d)
-	payload, err := json.Marshal(hook)
-	if err != nil {
-		return err
-	}
 
-	_, err = client.Put(fmt.Sprintf("2.0/repositories/%s/%s/hooks/%s",
+	err := saveHook(d, client, fmt.Sprintf("2.0/repositories/%s/%s/hooks/%s",
 		d.Get("owner").(string),
 		d.Get("repository").(string),
 		url.PathEscape(d.Id()),
-	), bytes.NewBuffer(payload))
+	))
 
 	if err != nil {
 		return err
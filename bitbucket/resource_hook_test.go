@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -49,6 +50,45 @@ func TestAccBitbucketHook_basic(t *testing.T) {
 	})
 }
 
+func TestAccBitbucketHook_generateSecret(t *testing.T) {
+	var hook Hook
+
+	testUser := os.Getenv("BITBUCKET_USERNAME")
+	testAccBitbucketHookConfig := fmt.Sprintf(`
+		resource "bitbucket_repository" "test_repo" {
+			owner = "%s"
+			name = "test-repo-for-webhook-test"
+		}
+		resource "bitbucket_hook" "test_repo_hook" {
+			owner = "%s"
+			repository = "${bitbucket_repository.test_repo.name}"
+			description = "Test hook for terraform"
+			url = "https://httpbin.org"
+			generate_secret = true
+			events = [
+				"repo:push",
+			]
+		}
+	`, testUser, testUser)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBitbucketHookDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBitbucketHookConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBitbucketHookExists("bitbucket_hook.test_repo_hook", &hook),
+					resource.TestCheckResourceAttrSet("bitbucket_hook.test_repo_hook", "generated_secret"),
+					resource.TestCheckResourceAttrSet("bitbucket_hook.test_repo_hook", "secret_hash"),
+					resource.TestCheckResourceAttr("bitbucket_hook.test_repo_hook", "skip_cert_verification", "false"),
+				),
+			},
+		},
+	})
+}
+
 func TestEncodesJsonCompletely(t *testing.T) {
 	hook := &Hook{
 		UUID:        uuid.NewV4().String(),
@@ -108,3 +148,75 @@ func testAccCheckBitbucketHookExists(n string, hook *Hook) resource.TestCheckFun
 		return nil
 	}
 }
+
+func TestResourceHookStateUpgradeV0(t *testing.T) {
+	expected := map[string]interface{}{
+		"url":                    "https://site.internal/",
+		"skip_cert_verification": true,
+		"generate_secret":        false,
+		"secret_hash":            "",
+	}
+
+	actual, err := resourceHookStateUpgradeV0(map[string]interface{}{
+		"url": "https://site.internal/",
+	}, nil)
+	if err != nil {
+		t.Fatalf("error migrating state: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("\n\nexpected:\n\n%#v\n\ngot:\n\n%#v\n\n", expected, actual)
+	}
+
+	actual, err = resourceHookStateUpgradeV0(map[string]interface{}{
+		"skip_cert_verification": false,
+	}, nil)
+	if err != nil {
+		t.Fatalf("error migrating state: %s", err)
+	}
+
+	if actual["skip_cert_verification"] != false {
+		t.Error("expected an explicit skip_cert_verification to be kept")
+	}
+}
+
+func TestEncodesSecretOnlyWhenSet(t *testing.T) {
+	hook := &Hook{URL: "https://site.internal/"}
+
+	payload, err := json.Marshal(hook)
+	if err != nil {
+		t.Fatalf("Failed to encode hook, %s", err)
+	}
+
+	if strings.Contains(string(payload), `"secret"`) {
+		t.Error("Rendered a secret that was not set.")
+	}
+
+	secret := generateHookSecretForTest(t)
+	hook.Secret = &secret
+
+	payload, err = json.Marshal(hook)
+	if err != nil {
+		t.Fatalf("Failed to encode hook, %s", err)
+	}
+
+	if !strings.Contains(string(payload), `"secret":"`+secret+`"`) {
+		t.Error("Did not render secret.")
+	}
+
+	if hashHookSecret(secret) == hashHookSecret(generateHookSecretForTest(t)) {
+		t.Error("Two generated secrets have the same hash.")
+	}
+
+	if hashHookSecret("") != "" {
+		t.Error("An empty secret should have an empty hash.")
+	}
+}
+
+func generateHookSecretForTest(t *testing.T) string {
+	secret, err := generateHookSecret()
+	if err != nil {
+		t.Fatalf("Failed to generate secret, %s", err)
+	}
+	return secret
+}
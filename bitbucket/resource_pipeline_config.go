@@ -0,0 +1,247 @@
+package bitbucket
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+// PipelinesBuildNumber is the struct we send to set the number of the next build
+type PipelinesBuildNumber struct {
+	Next int `json:"next"`
+}
+
+// RepositoryMainBranch is the part of a repository that tells its main branch
+type RepositoryMainBranch struct {
+	MainBranch *struct {
+		Name string `json:"name"`
+	} `json:"mainbranch"`
+}
+
+func resourcePipelineConfig() *schema.Resource {
+	return &schema.Resource{
+		Create: resourcePipelineConfigCreate,
+		Read:   resourcePipelineConfigRead,
+		Update: resourcePipelineConfigUpdate,
+		Delete: resourcePipelineConfigDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourcePipelineConfigImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultVisibilityTimeout),
+			Update: schema.DefaultTimeout(defaultVisibilityTimeout),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"workspace": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"repository": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"enabled": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"build_number": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+		},
+	}
+}
+
+// pipelinesYAMLExists tells if the main branch of the repository has a bitbucket-pipelines.yml, bitbucket refuses
+// to enable pipelines without one
+func pipelinesYAMLExists(client *Client, workspace, repository string) (bool, error) {
+	repoReq, err := client.Get(fmt.Sprintf("2.0/repositories/%s/%s?fields=mainbranch.name",
+		workspace,
+		repository,
+	))
+
+	if err != nil {
+		return false, err
+	}
+
+	var repo RepositoryMainBranch
+	body, readerr := ioutil.ReadAll(repoReq.Body)
+	if readerr != nil {
+		return false, readerr
+	}
+
+	decodeerr := json.Unmarshal(body, &repo)
+	if decodeerr != nil {
+		return false, decodeerr
+	}
+
+	// A repository without commits has no main branch yet.
+	if repo.MainBranch == nil || repo.MainBranch.Name == "" {
+		return false, nil
+	}
+
+	srcReq, err := client.Get(fmt.Sprintf("2.0/repositories/%s/%s/src/%s/bitbucket-pipelines.yml",
+		workspace,
+		repository,
+		repo.MainBranch.Name,
+	))
+
+	if srcReq != nil && srcReq.StatusCode == 404 {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// putPipelineConfig applies the configuration. Before enabling pipelines it waits for the bitbucket-pipelines.yml,
+// which may be committed by something this resource depends on.
+func putPipelineConfig(d *schema.ResourceData, client *Client, timeout string) error {
+	workspace := d.Get("workspace").(string)
+	repository := d.Get("repository").(string)
+
+	if d.HasChange("enabled") || d.IsNewResource() {
+		enabled := d.Get("enabled").(bool)
+
+		if enabled {
+			err := waitUntilVisible(fmt.Sprintf("bitbucket-pipelines.yml in %s/%s", workspace, repository), d.Timeout(timeout), func() (bool, error) {
+				return pipelinesYAMLExists(client, workspace, repository)
+			})
+
+			if err != nil {
+				return err
+			}
+		}
+
+		bytedata, err := json.Marshal(&PipelinesEnabled{Enabled: enabled})
+		if err != nil {
+			return err
+		}
+
+		_, err = client.Put(fmt.Sprintf("2.0/repositories/%s/%s/pipelines_config",
+			workspace,
+			repository,
+		), bytes.NewBuffer(bytedata))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	// Bitbucket does not return the build number, it is only sent when it changes.
+	if buildNumber := d.Get("build_number").(int); buildNumber > 0 && d.HasChange("build_number") {
+		bytedata, err := json.Marshal(&PipelinesBuildNumber{Next: buildNumber})
+		if err != nil {
+			return err
+		}
+
+		_, err = client.Put(fmt.Sprintf("2.0/repositories/%s/%s/pipelines_config/build_number",
+			workspace,
+			repository,
+		), bytes.NewBuffer(bytedata))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func resourcePipelineConfigCreate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	if err := putPipelineConfig(d, client, schema.TimeoutCreate); err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", d.Get("workspace").(string), d.Get("repository").(string)))
+
+	return resourcePipelineConfigRead(d, m)
+}
+
+func resourcePipelineConfigRead(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	pipelinesConfigReq, err := client.Get(fmt.Sprintf("2.0/repositories/%s/%s/pipelines_config",
+		d.Get("workspace").(string),
+		d.Get("repository").(string),
+	))
+
+	if pipelinesConfigReq != nil && pipelinesConfigReq.StatusCode == 404 {
+		d.SetId("")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var pipelinesConfig PipelinesEnabled
+	body, readerr := ioutil.ReadAll(pipelinesConfigReq.Body)
+	if readerr != nil {
+		return readerr
+	}
+
+	decodeerr := json.Unmarshal(body, &pipelinesConfig)
+	if decodeerr != nil {
+		return decodeerr
+	}
+
+	d.Set("enabled", pipelinesConfig.Enabled)
+
+	return nil
+}
+
+func resourcePipelineConfigUpdate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	if err := putPipelineConfig(d, client, schema.TimeoutUpdate); err != nil {
+		return err
+	}
+
+	return resourcePipelineConfigRead(d, m)
+}
+
+// resourcePipelineConfigDelete turns pipelines off again
+func resourcePipelineConfigDelete(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	bytedata, err := json.Marshal(&PipelinesEnabled{Enabled: false})
+	if err != nil {
+		return err
+	}
+
+	pipelinesConfigReq, err := client.Put(fmt.Sprintf("2.0/repositories/%s/%s/pipelines_config",
+		d.Get("workspace").(string),
+		d.Get("repository").(string),
+	), bytes.NewBuffer(bytedata))
+
+	if pipelinesConfigReq != nil && pipelinesConfigReq.StatusCode == 404 {
+		return nil
+	}
+
+	return err
+}
+
+func resourcePipelineConfigImport(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	idparts := strings.Split(d.Id(), "/")
+	if len(idparts) != 2 {
+		return nil, fmt.Errorf("Incorrect ID format, should match `workspace/repository`")
+	}
+
+	d.Set("workspace", idparts[0])
+	d.Set("repository", idparts[1])
+
+	return []*schema.ResourceData{d}, nil
+}
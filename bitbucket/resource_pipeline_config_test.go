@@ -0,0 +1,80 @@
+package bitbucket
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccBitbucketPipelineConfig_basic(t *testing.T) {
+	testUser := os.Getenv("BITBUCKET_USERNAME")
+	testRepository := os.Getenv("BITBUCKET_PIPELINES_REPOSITORY")
+	if testRepository == "" {
+		t.Skip("BITBUCKET_PIPELINES_REPOSITORY must name a repository with a bitbucket-pipelines.yml")
+	}
+
+	testAccBitbucketPipelineConfigConfig := func(enabled bool) string {
+		return fmt.Sprintf(`
+			resource "bitbucket_pipeline_config" "test" {
+				workspace = "%s"
+				repository = "%s"
+				enabled = %t
+				build_number = 100
+			}
+		`, testUser, testRepository, enabled)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBitbucketPipelineConfigConfig(true),
+				Check:  resource.TestCheckResourceAttr("bitbucket_pipeline_config.test", "enabled", "true"),
+			},
+			{
+				Config: testAccBitbucketPipelineConfigConfig(false),
+				Check:  resource.TestCheckResourceAttr("bitbucket_pipeline_config.test", "enabled", "false"),
+			},
+			{
+				ResourceName:            "bitbucket_pipeline_config.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"build_number"},
+			},
+		},
+	})
+}
+
+func TestAccBitbucketPipelineConfig_withoutPipelinesYAML(t *testing.T) {
+	testUser := os.Getenv("BITBUCKET_USERNAME")
+	testAccBitbucketPipelineConfigConfig := fmt.Sprintf(`
+		resource "bitbucket_repository" "test_repo" {
+			owner = "%s"
+			name = "test-repo-for-pipeline-config-test"
+		}
+
+		resource "bitbucket_pipeline_config" "test" {
+			workspace = bitbucket_repository.test_repo.owner
+			repository = bitbucket_repository.test_repo.name
+
+			timeouts {
+				create = "10s"
+			}
+		}
+	`, testUser)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccBitbucketPipelineConfigConfig,
+				ExpectError: regexp.MustCompile("bitbucket-pipelines.yml"),
+			},
+		},
+	})
+}
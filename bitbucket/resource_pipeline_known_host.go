@@ -0,0 +1,287 @@
+package bitbucket
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"golang.org/x/crypto/ssh"
+)
+
+// PipelineKnownHost is a host pipelines trust when connecting over SSH
+type PipelineKnownHost struct {
+	UUID      string                      `json:"uuid,omitempty"`
+	Hostname  string                      `json:"hostname"`
+	PublicKey *PipelineKnownHostPublicKey `json:"public_key"`
+}
+
+// PipelineKnownHostPublicKey is the host key of a known host
+type PipelineKnownHostPublicKey struct {
+	KeyType           string `json:"key_type"`
+	Key               string `json:"key"`
+	MD5Fingerprint    string `json:"md5_fingerprint,omitempty"`
+	SHA256Fingerprint string `json:"sha256_fingerprint,omitempty"`
+}
+
+func resourcePipelineKnownHost() *schema.Resource {
+	return &schema.Resource{
+		Create: resourcePipelineKnownHostCreate,
+		Read:   resourcePipelineKnownHostRead,
+		Update: resourcePipelineKnownHostUpdate,
+		Delete: resourcePipelineKnownHostDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourcePipelineKnownHostImport,
+		},
+
+		CustomizeDiff: resourcePipelineKnownHostDiff,
+
+		Schema: map[string]*schema.Schema{
+			"workspace": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"repository": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"uuid": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"hostname": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"public_key": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateSSHPublicKey,
+			},
+			"key_type": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"md5_fingerprint": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"sha256_fingerprint": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// newPipelineKnownHostPublicKey splits an authorized_keys line into the parts bitbucket wants, the fingerprints
+// are worked out here rather than by fetching the key from the host
+func newPipelineKnownHostPublicKey(publicKey string) (*PipelineKnownHostPublicKey, error) {
+	pub, _, err := parseSSHPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Fields(formatSSHPublicKey(pub))
+
+	return &PipelineKnownHostPublicKey{
+		KeyType:           parts[0],
+		Key:               parts[1],
+		MD5Fingerprint:    ssh.FingerprintLegacyMD5(pub),
+		SHA256Fingerprint: ssh.FingerprintSHA256(pub),
+	}, nil
+}
+
+func resourcePipelineKnownHostDiff(d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("public_key") {
+		for _, k := range []string{"key_type", "md5_fingerprint", "sha256_fingerprint"} {
+			if err := d.SetNewComputed(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	publicKey, err := newPipelineKnownHostPublicKey(d.Get("public_key").(string))
+	if err != nil {
+		return err
+	}
+
+	computed := map[string]string{
+		"key_type":           publicKey.KeyType,
+		"md5_fingerprint":    publicKey.MD5Fingerprint,
+		"sha256_fingerprint": publicKey.SHA256Fingerprint,
+	}
+
+	for k, v := range computed {
+		if d.Get(k).(string) == v {
+			continue
+		}
+
+		if err := d.SetNew(k, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newPipelineKnownHostFromResource(d *schema.ResourceData) (*PipelineKnownHost, error) {
+	publicKey, err := newPipelineKnownHostPublicKey(d.Get("public_key").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	return &PipelineKnownHost{
+		Hostname:  d.Get("hostname").(string),
+		PublicKey: publicKey,
+	}, nil
+}
+
+func resourcePipelineKnownHostCreate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	knownHost, err := newPipelineKnownHostFromResource(d)
+	if err != nil {
+		return err
+	}
+
+	bytedata, err := json.Marshal(knownHost)
+	if err != nil {
+		return err
+	}
+
+	knownHostReq, err := client.Post(fmt.Sprintf("2.0/repositories/%s/%s/pipelines_config/ssh/known_hosts",
+		d.Get("workspace").(string),
+		d.Get("repository").(string),
+	), bytes.NewBuffer(bytedata))
+
+	if err != nil {
+		return err
+	}
+
+	body, readerr := ioutil.ReadAll(knownHostReq.Body)
+	if readerr != nil {
+		return readerr
+	}
+
+	decodeerr := json.Unmarshal(body, &knownHost)
+	if decodeerr != nil {
+		return decodeerr
+	}
+
+	d.SetId(knownHost.UUID)
+
+	return resourcePipelineKnownHostRead(d, m)
+}
+
+func resourcePipelineKnownHostRead(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	knownHostReq, err := client.Get(fmt.Sprintf("2.0/repositories/%s/%s/pipelines_config/ssh/known_hosts/%s",
+		d.Get("workspace").(string),
+		d.Get("repository").(string),
+		url.PathEscape(d.Id()),
+	))
+
+	if knownHostReq != nil && knownHostReq.StatusCode == 404 {
+		d.SetId("")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var knownHost PipelineKnownHost
+	body, readerr := ioutil.ReadAll(knownHostReq.Body)
+	if readerr != nil {
+		return readerr
+	}
+
+	decodeerr := json.Unmarshal(body, &knownHost)
+	if decodeerr != nil {
+		return decodeerr
+	}
+
+	d.Set("uuid", knownHost.UUID)
+	d.Set("hostname", knownHost.Hostname)
+
+	if knownHost.PublicKey == nil {
+		return nil
+	}
+
+	// Keep the public key as configured, including its comment, unless the key itself changed.
+	remoteKey := knownHost.PublicKey.KeyType + " " + knownHost.PublicKey.Key
+	if !sameSSHPublicKey(d.Get("public_key").(string), remoteKey) {
+		d.Set("public_key", remoteKey)
+	}
+
+	if publicKey, err := newPipelineKnownHostPublicKey(remoteKey); err == nil {
+		d.Set("key_type", publicKey.KeyType)
+		d.Set("md5_fingerprint", publicKey.MD5Fingerprint)
+		d.Set("sha256_fingerprint", publicKey.SHA256Fingerprint)
+	}
+
+	return nil
+}
+
+func resourcePipelineKnownHostUpdate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	knownHost, err := newPipelineKnownHostFromResource(d)
+	if err != nil {
+		return err
+	}
+
+	bytedata, err := json.Marshal(knownHost)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Put(fmt.Sprintf("2.0/repositories/%s/%s/pipelines_config/ssh/known_hosts/%s",
+		d.Get("workspace").(string),
+		d.Get("repository").(string),
+		url.PathEscape(d.Id()),
+	), bytes.NewBuffer(bytedata))
+
+	if err != nil {
+		return err
+	}
+
+	return resourcePipelineKnownHostRead(d, m)
+}
+
+func resourcePipelineKnownHostDelete(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	knownHostReq, err := client.Delete(fmt.Sprintf("2.0/repositories/%s/%s/pipelines_config/ssh/known_hosts/%s",
+		d.Get("workspace").(string),
+		d.Get("repository").(string),
+		url.PathEscape(d.Id()),
+	))
+
+	if knownHostReq != nil && knownHostReq.StatusCode == 404 {
+		return nil
+	}
+
+	return err
+}
+
+func resourcePipelineKnownHostImport(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	idparts := strings.SplitN(d.Id(), "/", 3)
+	if len(idparts) != 3 {
+		return nil, fmt.Errorf("Incorrect ID format, should match `workspace/repository/{uuid}`")
+	}
+
+	d.Set("workspace", idparts[0])
+	d.Set("repository", idparts[1])
+	d.SetId(idparts[2])
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,40 @@
+package bitbucket
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccBitbucketPipelineKnownHost_basic(t *testing.T) {
+	testUser := os.Getenv("BITBUCKET_USERNAME")
+	testAccBitbucketPipelineKnownHostConfig := fmt.Sprintf(`
+		resource "bitbucket_repository" "test_repo" {
+			owner = "%s"
+			name = "test-repo-for-pipeline-known-host-test"
+		}
+
+		resource "bitbucket_pipeline_known_host" "test" {
+			workspace = bitbucket_repository.test_repo.owner
+			repository = bitbucket_repository.test_repo.name
+			hostname = "git.example.com"
+			public_key = "%s"
+		}
+	`, testUser, testSSHPublicKey)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBitbucketPipelineKnownHostConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("bitbucket_pipeline_known_host.test", "key_type", "ssh-ed25519"),
+					resource.TestCheckResourceAttr("bitbucket_pipeline_known_host.test", "sha256_fingerprint", "SHA256:hv6zCeEbuB2Gcjm3z0CyZn8P7uW09SNBJKGLX3d3Hr0"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,363 @@
+package bitbucket
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+// defaultPipelineRunTimeout is how long we wait by default for a pipeline run to complete
+const defaultPipelineRunTimeout = 30 * time.Minute
+
+// Pipeline is a single run of a pipeline
+type Pipeline struct {
+	UUID        string             `json:"uuid,omitempty"`
+	BuildNumber int                `json:"build_number,omitempty"`
+	Target      *PipelineRefTarget `json:"target,omitempty"`
+	Variables   []PipelineVariable `json:"variables,omitempty"`
+	State       *PipelineState     `json:"state,omitempty"`
+}
+
+// PipelineState is where a run is at, the result is only there once it completed
+type PipelineState struct {
+	Name   string               `json:"name"`
+	Result *PipelineStateResult `json:"result,omitempty"`
+}
+
+// PipelineStateResult is how a completed run ended
+type PipelineStateResult struct {
+	Name string `json:"name"`
+}
+
+func resourcePipelineRun() *schema.Resource {
+	return &schema.Resource{
+		Create: resourcePipelineRunCreate,
+		Read:   resourcePipelineRunRead,
+		Delete: resourcePipelineRunDelete,
+
+		CustomizeDiff: resourcePipelineRunDiff,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultPipelineRunTimeout),
+		},
+
+		// A run can not be changed once it started, every argument starts a new one.
+		Schema: map[string]*schema.Schema{
+			"workspace": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"repository": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"ref_type": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Default:  "branch",
+				ValidateFunc: validation.StringInSlice([]string{
+					"branch",
+					"tag",
+				},
+					false),
+			},
+			"ref_name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"selector_type": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					"custom",
+					"branches",
+					"tags",
+					"default",
+				},
+					false),
+			},
+			"selector_pattern": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"variable": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				ForceNew: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validateVariableKey,
+						},
+						"value": {
+							Type:      schema.TypeString,
+							Required:  true,
+							Sensitive: true,
+						},
+						"secured": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+					},
+				},
+			},
+			"triggers": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				ForceNew: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"uuid": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"build_number": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"state": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"result": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourcePipelineRunDiff(d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("selector_type") || !d.NewValueKnown("selector_pattern") {
+		return nil
+	}
+
+	selectorType := d.Get("selector_type").(string)
+	selectorPattern := d.Get("selector_pattern").(string)
+
+	if selectorType == "custom" && selectorPattern == "" {
+		return fmt.Errorf("selector_pattern must be the name of the custom pipeline when selector_type is custom")
+	}
+
+	if selectorType == "" && selectorPattern != "" {
+		return fmt.Errorf("selector_pattern needs a selector_type")
+	}
+
+	return nil
+}
+
+func newPipelineRunFromResource(d *schema.ResourceData) *Pipeline {
+	pipeline := &Pipeline{
+		Target: &PipelineRefTarget{
+			Type:    "pipeline_ref_target",
+			RefType: d.Get("ref_type").(string),
+			RefName: d.Get("ref_name").(string),
+		},
+	}
+
+	// Without a selector bitbucket runs the pipeline the ref would run when pushed.
+	if selectorType := d.Get("selector_type").(string); selectorType != "" {
+		pipeline.Target.Selector = &PipelineSelector{
+			Type:    selectorType,
+			Pattern: d.Get("selector_pattern").(string),
+		}
+	}
+
+	for _, item := range d.Get("variable").(*schema.Set).List() {
+		variable := item.(map[string]interface{})
+		pipeline.Variables = append(pipeline.Variables, PipelineVariable{
+			Key:     variable["key"].(string),
+			Value:   variable["value"].(string),
+			Secured: variable["secured"].(bool),
+		})
+	}
+
+	return pipeline
+}
+
+func getPipelineRun(client *Client, workspace, repository, uuid string) (*Pipeline, error) {
+	pipelineReq, err := client.Get(fmt.Sprintf("2.0/repositories/%s/%s/pipelines/%s",
+		workspace,
+		repository,
+		url.PathEscape(uuid),
+	))
+
+	if pipelineReq != nil && pipelineReq.StatusCode == 404 {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var pipeline Pipeline
+	body, readerr := ioutil.ReadAll(pipelineReq.Body)
+	if readerr != nil {
+		return nil, readerr
+	}
+
+	decodeerr := json.Unmarshal(body, &pipeline)
+	if decodeerr != nil {
+		return nil, decodeerr
+	}
+
+	return &pipeline, nil
+}
+
+// waitForPipelineRun polls refresh until the run completed. Bitbucket has a few states for runs that did not
+// finish yet, anything short of COMPLETED counts as still running.
+func waitForPipelineRun(description string, timeout time.Duration, refresh func() (*Pipeline, error)) (*Pipeline, error) {
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{"running"},
+		Target:  []string{"COMPLETED"},
+		Refresh: func() (interface{}, string, error) {
+			pipeline, err := refresh()
+			if err != nil {
+				return nil, "", err
+			}
+
+			if pipeline == nil {
+				return nil, "", fmt.Errorf("%s no longer exists", description)
+			}
+
+			if pipeline.State == nil || pipeline.State.Name != "COMPLETED" {
+				log.Printf("[DEBUG] Waiting for %s to complete", description)
+				return pipeline, "running", nil
+			}
+
+			return pipeline, "COMPLETED", nil
+		},
+		Timeout: timeout,
+	}
+
+	pipeline, err := stateConf.WaitForState()
+	if err != nil {
+		return nil, fmt.Errorf("waiting for %s to complete: %s", description, err)
+	}
+
+	return pipeline.(*Pipeline), nil
+}
+
+// pipelineRunResult is the result of a completed run, an empty string before that
+func pipelineRunResult(pipeline *Pipeline) string {
+	if pipeline.State == nil || pipeline.State.Result == nil {
+		return ""
+	}
+
+	return pipeline.State.Result.Name
+}
+
+func resourcePipelineRunCreate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+	workspace := d.Get("workspace").(string)
+	repository := d.Get("repository").(string)
+
+	bytedata, err := json.Marshal(newPipelineRunFromResource(d))
+	if err != nil {
+		return err
+	}
+
+	pipelineReq, err := client.Post(fmt.Sprintf("2.0/repositories/%s/%s/pipelines/",
+		workspace,
+		repository,
+	), bytes.NewBuffer(bytedata))
+
+	if err != nil {
+		return err
+	}
+
+	var pipeline Pipeline
+	body, readerr := ioutil.ReadAll(pipelineReq.Body)
+	if readerr != nil {
+		return readerr
+	}
+
+	decodeerr := json.Unmarshal(body, &pipeline)
+	if decodeerr != nil {
+		return decodeerr
+	}
+
+	// The id is set before waiting, a run that fails or times out is tainted and runs again on the next apply.
+	d.SetId(pipeline.UUID)
+
+	description := fmt.Sprintf("pipeline #%d of %s/%s", pipeline.BuildNumber, workspace, repository)
+	completed, err := waitForPipelineRun(description, d.Timeout(schema.TimeoutCreate), func() (*Pipeline, error) {
+		return getPipelineRun(client, workspace, repository, pipeline.UUID)
+	})
+
+	if err != nil {
+		// Stop the run so it does not overlap with the one the next apply starts.
+		_, stoperr := client.Post(fmt.Sprintf("2.0/repositories/%s/%s/pipelines/%s/stopPipeline",
+			workspace,
+			repository,
+			url.PathEscape(pipeline.UUID),
+		), bytes.NewBuffer(nil))
+
+		if stoperr != nil {
+			log.Printf("[WARN] Could not stop %s: %s", description, stoperr)
+		}
+
+		return err
+	}
+
+	setPipelineRun(d, completed)
+
+	if result := pipelineRunResult(completed); result != "SUCCESSFUL" {
+		return fmt.Errorf("%s completed with result %s", description, result)
+	}
+
+	return nil
+}
+
+func setPipelineRun(d *schema.ResourceData, pipeline *Pipeline) {
+	d.Set("uuid", pipeline.UUID)
+	d.Set("build_number", pipeline.BuildNumber)
+	d.Set("result", pipelineRunResult(pipeline))
+
+	if pipeline.State != nil {
+		d.Set("state", pipeline.State.Name)
+	}
+}
+
+func resourcePipelineRunRead(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	pipeline, err := getPipelineRun(client, d.Get("workspace").(string), d.Get("repository").(string), d.Id())
+	if err != nil {
+		return err
+	}
+
+	// Bitbucket cleans up old runs, losing the record of one is no reason to run it again.
+	if pipeline == nil {
+		log.Printf("[WARN] Pipeline run %s no longer exists, keeping it in state", d.Id())
+		return nil
+	}
+
+	setPipelineRun(d, pipeline)
+
+	return nil
+}
+
+// resourcePipelineRunDelete only forgets the run, bitbucket keeps the history of its pipelines
+func resourcePipelineRunDelete(d *schema.ResourceData, m interface{}) error {
+	return nil
+}
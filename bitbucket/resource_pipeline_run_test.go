@@ -0,0 +1,115 @@
+package bitbucket
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccBitbucketPipelineRun_basic(t *testing.T) {
+	testUser := os.Getenv("BITBUCKET_USERNAME")
+	testRepository := os.Getenv("BITBUCKET_PIPELINES_REPOSITORY")
+	if testRepository == "" {
+		t.Skip("BITBUCKET_PIPELINES_REPOSITORY must name a repository with a nightly custom pipeline")
+	}
+
+	testAccBitbucketPipelineRunConfig := func(trigger string) string {
+		return fmt.Sprintf(`
+			resource "bitbucket_pipeline_run" "test" {
+				workspace = "%s"
+				repository = "%s"
+				ref_name = "master"
+				selector_type = "custom"
+				selector_pattern = "nightly"
+
+				variable {
+					key = "GREETING"
+					value = "hello"
+				}
+
+				triggers = {
+					run = "%s"
+				}
+			}
+		`, testUser, testRepository, trigger)
+	}
+
+	var firstUUID string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBitbucketPipelineRunConfig("first"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("bitbucket_pipeline_run.test", "state", "COMPLETED"),
+					resource.TestCheckResourceAttr("bitbucket_pipeline_run.test", "result", "SUCCESSFUL"),
+					resource.TestCheckResourceAttrSet("bitbucket_pipeline_run.test", "build_number"),
+					testAccStorePipelineRunUUID("bitbucket_pipeline_run.test", &firstUUID),
+				),
+			},
+			{
+				Config: testAccBitbucketPipelineRunConfig("second"),
+				Check: func(s *terraform.State) error {
+					if s.RootModule().Resources["bitbucket_pipeline_run.test"].Primary.ID == firstUUID {
+						return fmt.Errorf("expected changing triggers to start a new run")
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func testAccStorePipelineRunUUID(n string, uuid *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found %s", n)
+		}
+
+		*uuid = rs.Primary.ID
+		return nil
+	}
+}
+
+func TestWaitForPipelineRun(t *testing.T) {
+	polls := 0
+	pipeline, err := waitForPipelineRun("test run", time.Minute, func() (*Pipeline, error) {
+		polls++
+		if polls < 3 {
+			return &Pipeline{State: &PipelineState{Name: "IN_PROGRESS"}}, nil
+		}
+		return &Pipeline{State: &PipelineState{Name: "COMPLETED", Result: &PipelineStateResult{Name: "FAILED"}}}, nil
+	})
+
+	if err != nil {
+		t.Fatalf("expected the run to complete, got %s", err)
+	}
+
+	if result := pipelineRunResult(pipeline); result != "FAILED" {
+		t.Errorf("expected the result of the completed run, got %q", result)
+	}
+}
+
+func TestWaitForPipelineRunGone(t *testing.T) {
+	_, err := waitForPipelineRun("test run", time.Minute, func() (*Pipeline, error) {
+		return nil, nil
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "test run no longer exists") {
+		t.Errorf("expected an error for a vanished run, got %v", err)
+	}
+}
+
+func TestPipelineRunResult(t *testing.T) {
+	if result := pipelineRunResult(&Pipeline{State: &PipelineState{Name: "PENDING"}}); result != "" {
+		t.Errorf("expected no result for a pending run, got %q", result)
+	}
+}
@@ -0,0 +1,271 @@
+package bitbucket
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// PipelineRunner is a self-hosted runner of a workspace or repository
+type PipelineRunner struct {
+	UUID        string                     `json:"uuid,omitempty"`
+	Name        string                     `json:"name"`
+	Labels      []string                   `json:"labels,omitempty"`
+	State       *PipelineRunnerState       `json:"state,omitempty"`
+	OAuthClient *PipelineRunnerOAuthClient `json:"oauth_client,omitempty"`
+}
+
+// PipelineRunnerState tells if a runner is registered and online
+type PipelineRunnerState struct {
+	Status string `json:"status"`
+}
+
+// PipelineRunnerOAuthClient is what a runner registers with, bitbucket only returns the secret when the runner is
+// created
+type PipelineRunnerOAuthClient struct {
+	ID            string `json:"id"`
+	Secret        string `json:"secret,omitempty"`
+	TokenEndpoint string `json:"token_endpoint,omitempty"`
+	Audience      string `json:"audience,omitempty"`
+}
+
+// pipelineRunnerRequiredLabel is the label every self-hosted runner has
+const pipelineRunnerRequiredLabel = "self.hosted"
+
+func resourcePipelineRunner() *schema.Resource {
+	return &schema.Resource{
+		Create: resourcePipelineRunnerCreate,
+		Read:   resourcePipelineRunnerRead,
+		Update: resourcePipelineRunnerUpdate,
+		Delete: resourcePipelineRunnerDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourcePipelineRunnerImport,
+		},
+
+		CustomizeDiff: resourcePipelineRunnerDiff,
+
+		Schema: map[string]*schema.Schema{
+			"workspace": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"repository": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"uuid": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"labels": {
+				Type:     schema.TypeSet,
+				Required: true,
+				ForceNew: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Set: schema.HashString,
+			},
+			"status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"oauth_client_id": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"oauth_client_secret": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"oauth_token_endpoint": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"oauth_audience": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// pipelineRunnersURL is where the runners of a repository live, or of the workspace without a repository. Runners
+// are not part of the 2.0 api.
+func pipelineRunnersURL(workspace, repository string) string {
+	if repository == "" {
+		return fmt.Sprintf("internal/workspaces/%s/pipelines-config/runners", workspace)
+	}
+
+	return fmt.Sprintf("internal/repositories/%s/%s/pipelines-config/runners", workspace, repository)
+}
+
+func pipelineRunnerURL(d *schema.ResourceData) string {
+	return fmt.Sprintf("%s/%s",
+		pipelineRunnersURL(d.Get("workspace").(string), d.Get("repository").(string)),
+		url.PathEscape(d.Id()),
+	)
+}
+
+func resourcePipelineRunnerDiff(d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("labels") {
+		return nil
+	}
+
+	for _, label := range d.Get("labels").(*schema.Set).List() {
+		if label.(string) == pipelineRunnerRequiredLabel {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("labels must include %s", pipelineRunnerRequiredLabel)
+}
+
+func resourcePipelineRunnerCreate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	runner := &PipelineRunner{
+		Name: d.Get("name").(string),
+	}
+
+	for _, label := range d.Get("labels").(*schema.Set).List() {
+		runner.Labels = append(runner.Labels, label.(string))
+	}
+
+	bytedata, err := json.Marshal(runner)
+	if err != nil {
+		return err
+	}
+
+	runnerReq, err := client.Post(pipelineRunnersURL(d.Get("workspace").(string), d.Get("repository").(string)),
+		bytes.NewBuffer(bytedata))
+
+	if err != nil {
+		return err
+	}
+
+	body, readerr := ioutil.ReadAll(runnerReq.Body)
+	if readerr != nil {
+		return readerr
+	}
+
+	decodeerr := json.Unmarshal(body, &runner)
+	if decodeerr != nil {
+		return decodeerr
+	}
+
+	d.SetId(runner.UUID)
+
+	// The secret is only returned here, reads keep what we got.
+	if runner.OAuthClient != nil {
+		d.Set("oauth_client_secret", runner.OAuthClient.Secret)
+	}
+
+	return resourcePipelineRunnerRead(d, m)
+}
+
+func resourcePipelineRunnerRead(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	runnerReq, err := client.Get(pipelineRunnerURL(d))
+
+	if runnerReq != nil && runnerReq.StatusCode == 404 {
+		d.SetId("")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var runner PipelineRunner
+	body, readerr := ioutil.ReadAll(runnerReq.Body)
+	if readerr != nil {
+		return readerr
+	}
+
+	decodeerr := json.Unmarshal(body, &runner)
+	if decodeerr != nil {
+		return decodeerr
+	}
+
+	d.Set("uuid", runner.UUID)
+	d.Set("name", runner.Name)
+	d.Set("labels", runner.Labels)
+
+	if runner.State != nil {
+		d.Set("status", runner.State.Status)
+	}
+
+	if runner.OAuthClient != nil {
+		d.Set("oauth_client_id", runner.OAuthClient.ID)
+		d.Set("oauth_token_endpoint", runner.OAuthClient.TokenEndpoint)
+		d.Set("oauth_audience", runner.OAuthClient.Audience)
+	}
+
+	return nil
+}
+
+func resourcePipelineRunnerUpdate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	bytedata, err := json.Marshal(&PipelineRunner{
+		Name: d.Get("name").(string),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Put(pipelineRunnerURL(d), bytes.NewBuffer(bytedata))
+	if err != nil {
+		return err
+	}
+
+	return resourcePipelineRunnerRead(d, m)
+}
+
+func resourcePipelineRunnerDelete(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	runnerReq, err := client.Delete(pipelineRunnerURL(d))
+
+	if runnerReq != nil && runnerReq.StatusCode == 404 {
+		return nil
+	}
+
+	return err
+}
+
+// resourcePipelineRunnerImport takes `workspace/{uuid}` for workspace runners and `workspace/repository/{uuid}` for
+// repository runners
+func resourcePipelineRunnerImport(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	idparts := strings.Split(d.Id(), "/")
+
+	switch len(idparts) {
+	case 2:
+		d.Set("workspace", idparts[0])
+		d.SetId(idparts[1])
+	case 3:
+		d.Set("workspace", idparts[0])
+		d.Set("repository", idparts[1])
+		d.SetId(idparts[2])
+	default:
+		return nil, fmt.Errorf("Incorrect ID format, should match `workspace/{uuid}` or `workspace/repository/{uuid}`")
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
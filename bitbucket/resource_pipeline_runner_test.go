@@ -0,0 +1,84 @@
+package bitbucket
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccBitbucketPipelineRunner_basic(t *testing.T) {
+	testUser := os.Getenv("BITBUCKET_USERNAME")
+	testAccBitbucketPipelineRunnerConfig := func(name string) string {
+		return fmt.Sprintf(`
+			resource "bitbucket_repository" "test_repo" {
+				owner = "%s"
+				name = "test-repo-for-pipeline-runner-test"
+			}
+
+			resource "bitbucket_pipeline_runner" "workspace" {
+				workspace = bitbucket_repository.test_repo.owner
+				name = "%s"
+				labels = ["self.hosted", "linux"]
+			}
+
+			resource "bitbucket_pipeline_runner" "repository" {
+				workspace = bitbucket_repository.test_repo.owner
+				repository = bitbucket_repository.test_repo.name
+				name = "%s"
+				labels = ["self.hosted", "linux", "docker"]
+			}
+		`, testUser, name, name)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBitbucketPipelineRunnerConfig("terraform-test-runner"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("bitbucket_pipeline_runner.workspace", "status", "UNREGISTERED"),
+					resource.TestCheckResourceAttrSet("bitbucket_pipeline_runner.workspace", "oauth_client_id"),
+					resource.TestCheckResourceAttrSet("bitbucket_pipeline_runner.workspace", "oauth_client_secret"),
+					resource.TestCheckResourceAttr("bitbucket_pipeline_runner.repository", "labels.#", "3"),
+					resource.TestCheckResourceAttrSet("bitbucket_pipeline_runner.repository", "oauth_client_secret"),
+				),
+			},
+			{
+				Config: testAccBitbucketPipelineRunnerConfig("terraform-test-runner-renamed"),
+				Check:  resource.TestCheckResourceAttr("bitbucket_pipeline_runner.repository", "name", "terraform-test-runner-renamed"),
+			},
+			{
+				ResourceName:            "bitbucket_pipeline_runner.repository",
+				ImportState:             true,
+				ImportStateIdFunc:       testAccBitbucketPipelineRunnerImportId("bitbucket_pipeline_runner.repository"),
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"oauth_client_secret"},
+			},
+		},
+	})
+}
+
+func testAccBitbucketPipelineRunnerImportId(n string) resource.ImportStateIdFunc {
+	return func(s *terraform.State) (string, error) {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return "", fmt.Errorf("Not found %s", n)
+		}
+
+		return fmt.Sprintf("%s/%s/%s", rs.Primary.Attributes["workspace"], rs.Primary.Attributes["repository"], rs.Primary.ID), nil
+	}
+}
+
+func TestPipelineRunnersURL(t *testing.T) {
+	if actual := pipelineRunnersURL("gob", ""); actual != "internal/workspaces/gob/pipelines-config/runners" {
+		t.Errorf("unexpected url for workspace runners: %s", actual)
+	}
+
+	if actual := pipelineRunnersURL("gob", "illusions"); actual != "internal/repositories/gob/illusions/pipelines-config/runners" {
+		t.Errorf("unexpected url for repository runners: %s", actual)
+	}
+}
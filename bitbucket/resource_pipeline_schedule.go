@@ -0,0 +1,329 @@
+package bitbucket
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+// PipelineSchedule runs a pipeline on a cron schedule
+type PipelineSchedule struct {
+	Type        string             `json:"type"`
+	UUID        string             `json:"uuid,omitempty"`
+	Enabled     bool               `json:"enabled"`
+	Target      *PipelineRefTarget `json:"target,omitempty"`
+	CronPattern string             `json:"cron_pattern,omitempty"`
+}
+
+// PipelineRefTarget is the branch and pipeline a schedule or run builds
+type PipelineRefTarget struct {
+	Type     string            `json:"type"`
+	RefType  string            `json:"ref_type"`
+	RefName  string            `json:"ref_name"`
+	Selector *PipelineSelector `json:"selector,omitempty"`
+}
+
+// PipelineSelector picks the pipeline out of the bitbucket-pipelines.yml
+type PipelineSelector struct {
+	Type    string `json:"type"`
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// pipelineScheduleCronFields are the fields of a schedule's cron pattern with the range of their plain numbers
+var pipelineScheduleCronFields = []struct {
+	Name     string
+	Min, Max int
+}{
+	{"seconds", 0, 59},
+	{"minutes", 0, 59},
+	{"hours", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 1, 7},
+	{"year", 1970, 2099},
+}
+
+var pipelineScheduleCronFieldRegexp = regexp.MustCompile(`^[0-9A-Za-z*?,/#-]+$`)
+
+// validatePipelineScheduleCron checks the Quartz style cron patterns bitbucket takes: seconds, minutes, hours, day of
+// month, month, day of week and year, where exactly one of the days is `?`.
+func validatePipelineScheduleCron(v interface{}, k string) (ws []string, errors []error) {
+	fields := strings.Fields(v.(string))
+
+	if len(fields) != len(pipelineScheduleCronFields) {
+		errors = append(errors, fmt.Errorf("%s must have 7 fields: seconds, minutes, hours, day of month, month, day of week and year, got %q", k, v))
+		return
+	}
+
+	for i, field := range fields {
+		spec := pipelineScheduleCronFields[i]
+
+		if !pipelineScheduleCronFieldRegexp.MatchString(field) {
+			errors = append(errors, fmt.Errorf("%s has an invalid %s field %q", k, spec.Name, field))
+			continue
+		}
+
+		for _, item := range strings.Split(field, ",") {
+			// Step values are counts rather than values of the field.
+			value := strings.SplitN(item, "/", 2)[0]
+
+			for _, number := range strings.Split(value, "-") {
+				n, err := strconv.Atoi(number)
+				if err != nil {
+					continue
+				}
+
+				if n < spec.Min || n > spec.Max {
+					errors = append(errors, fmt.Errorf("%s has %d in the %s field, which must be between %d and %d", k, n, spec.Name, spec.Min, spec.Max))
+				}
+			}
+		}
+	}
+
+	if (fields[3] == "?") == (fields[5] == "?") {
+		errors = append(errors, fmt.Errorf("%s must have `?` in exactly one of the day of month and day of week fields", k))
+	}
+
+	return
+}
+
+func resourcePipelineSchedule() *schema.Resource {
+	return &schema.Resource{
+		Create: resourcePipelineScheduleCreate,
+		Read:   resourcePipelineScheduleRead,
+		Update: resourcePipelineScheduleUpdate,
+		Delete: resourcePipelineScheduleDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourcePipelineScheduleImport,
+		},
+
+		CustomizeDiff: resourcePipelineScheduleDiff,
+
+		// Bitbucket can only turn schedules on and off, anything else recreates them.
+		Schema: map[string]*schema.Schema{
+			"workspace": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"repository": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"uuid": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"branch": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"selector_type": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Default:  "custom",
+				ValidateFunc: validation.StringInSlice([]string{
+					"custom",
+					"branches",
+					"default",
+				},
+					false),
+			},
+			"selector_pattern": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"cron_pattern": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validatePipelineScheduleCron,
+			},
+			"enabled": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+		},
+	}
+}
+
+func resourcePipelineScheduleDiff(d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("selector_type") || !d.NewValueKnown("selector_pattern") {
+		return nil
+	}
+
+	selectorType := d.Get("selector_type").(string)
+	selectorPattern := d.Get("selector_pattern").(string)
+
+	if selectorType == "custom" && selectorPattern == "" {
+		return fmt.Errorf("selector_pattern must be the name of the custom pipeline when selector_type is custom")
+	}
+
+	if selectorType == "default" && selectorPattern != "" {
+		return fmt.Errorf("selector_pattern can not be set when selector_type is default")
+	}
+
+	return nil
+}
+
+func newPipelineScheduleFromResource(d *schema.ResourceData) *PipelineSchedule {
+	return &PipelineSchedule{
+		Type:    "pipeline_schedule",
+		Enabled: d.Get("enabled").(bool),
+		Target: &PipelineRefTarget{
+			Type:    "pipeline_ref_target",
+			RefType: "branch",
+			RefName: d.Get("branch").(string),
+			Selector: &PipelineSelector{
+				Type:    d.Get("selector_type").(string),
+				Pattern: d.Get("selector_pattern").(string),
+			},
+		},
+		CronPattern: d.Get("cron_pattern").(string),
+	}
+}
+
+func resourcePipelineScheduleCreate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+	schedule := newPipelineScheduleFromResource(d)
+
+	bytedata, err := json.Marshal(schedule)
+	if err != nil {
+		return err
+	}
+
+	scheduleReq, err := client.Post(fmt.Sprintf("2.0/repositories/%s/%s/pipelines_config/schedules/",
+		d.Get("workspace").(string),
+		d.Get("repository").(string),
+	), bytes.NewBuffer(bytedata))
+
+	if err != nil {
+		return err
+	}
+
+	body, readerr := ioutil.ReadAll(scheduleReq.Body)
+	if readerr != nil {
+		return readerr
+	}
+
+	decodeerr := json.Unmarshal(body, &schedule)
+	if decodeerr != nil {
+		return decodeerr
+	}
+
+	d.SetId(schedule.UUID)
+
+	return resourcePipelineScheduleRead(d, m)
+}
+
+func resourcePipelineScheduleRead(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	scheduleReq, err := client.Get(fmt.Sprintf("2.0/repositories/%s/%s/pipelines_config/schedules/%s",
+		d.Get("workspace").(string),
+		d.Get("repository").(string),
+		url.PathEscape(d.Id()),
+	))
+
+	if scheduleReq != nil && scheduleReq.StatusCode == 404 {
+		d.SetId("")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var schedule PipelineSchedule
+	body, readerr := ioutil.ReadAll(scheduleReq.Body)
+	if readerr != nil {
+		return readerr
+	}
+
+	decodeerr := json.Unmarshal(body, &schedule)
+	if decodeerr != nil {
+		return decodeerr
+	}
+
+	d.Set("uuid", schedule.UUID)
+	d.Set("enabled", schedule.Enabled)
+	d.Set("cron_pattern", schedule.CronPattern)
+
+	if schedule.Target != nil {
+		d.Set("branch", schedule.Target.RefName)
+
+		if schedule.Target.Selector != nil {
+			d.Set("selector_type", schedule.Target.Selector.Type)
+			d.Set("selector_pattern", schedule.Target.Selector.Pattern)
+		}
+	}
+
+	return nil
+}
+
+func resourcePipelineScheduleUpdate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	bytedata, err := json.Marshal(&PipelineSchedule{
+		Type:    "pipeline_schedule",
+		Enabled: d.Get("enabled").(bool),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Put(fmt.Sprintf("2.0/repositories/%s/%s/pipelines_config/schedules/%s",
+		d.Get("workspace").(string),
+		d.Get("repository").(string),
+		url.PathEscape(d.Id()),
+	), bytes.NewBuffer(bytedata))
+
+	if err != nil {
+		return err
+	}
+
+	return resourcePipelineScheduleRead(d, m)
+}
+
+func resourcePipelineScheduleDelete(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	scheduleReq, err := client.Delete(fmt.Sprintf("2.0/repositories/%s/%s/pipelines_config/schedules/%s",
+		d.Get("workspace").(string),
+		d.Get("repository").(string),
+		url.PathEscape(d.Id()),
+	))
+
+	if scheduleReq != nil && scheduleReq.StatusCode == 404 {
+		return nil
+	}
+
+	return err
+}
+
+func resourcePipelineScheduleImport(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	idparts := strings.SplitN(d.Id(), "/", 3)
+	if len(idparts) != 3 {
+		return nil, fmt.Errorf("Incorrect ID format, should match `workspace/repository/{uuid}`")
+	}
+
+	d.Set("workspace", idparts[0])
+	d.Set("repository", idparts[1])
+	d.SetId(idparts[2])
+
+	return []*schema.ResourceData{d}, nil
+}
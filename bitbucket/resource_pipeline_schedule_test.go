@@ -0,0 +1,88 @@
+package bitbucket
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccBitbucketPipelineSchedule_basic(t *testing.T) {
+	testUser := os.Getenv("BITBUCKET_USERNAME")
+	testRepository := os.Getenv("BITBUCKET_PIPELINES_REPOSITORY")
+	if testRepository == "" {
+		t.Skip("BITBUCKET_PIPELINES_REPOSITORY must name a repository with a nightly custom pipeline")
+	}
+
+	testAccBitbucketPipelineScheduleConfig := func(enabled bool) string {
+		return fmt.Sprintf(`
+			resource "bitbucket_pipeline_schedule" "test" {
+				workspace = "%s"
+				repository = "%s"
+				branch = "master"
+				selector_pattern = "nightly"
+				cron_pattern = "0 0 2 * * ? *"
+				enabled = %t
+			}
+		`, testUser, testRepository, enabled)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBitbucketPipelineScheduleConfig(true),
+				Check:  resource.TestCheckResourceAttr("bitbucket_pipeline_schedule.test", "enabled", "true"),
+			},
+			{
+				Config: testAccBitbucketPipelineScheduleConfig(false),
+				Check:  resource.TestCheckResourceAttr("bitbucket_pipeline_schedule.test", "enabled", "false"),
+			},
+			{
+				ResourceName:      "bitbucket_pipeline_schedule.test",
+				ImportState:       true,
+				ImportStateIdFunc: testAccBitbucketPipelineScheduleImportId,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccBitbucketPipelineScheduleImportId(s *terraform.State) (string, error) {
+	rs, ok := s.RootModule().Resources["bitbucket_pipeline_schedule.test"]
+	if !ok {
+		return "", fmt.Errorf("Not found %s", "bitbucket_pipeline_schedule.test")
+	}
+
+	return fmt.Sprintf("%s/%s/%s", rs.Primary.Attributes["workspace"], rs.Primary.Attributes["repository"], rs.Primary.ID), nil
+}
+
+func TestValidatePipelineScheduleCron(t *testing.T) {
+	for _, pattern := range []string{
+		"0 0 2 * * ? *",
+		"0 30 9 ? * 2-6 *",
+		"0 0/15 * * * ? *",
+		"0 0 12 1,15 * ? 2030",
+	} {
+		if _, errors := validatePipelineScheduleCron(pattern, "cron_pattern"); len(errors) > 0 {
+			t.Errorf("expected %s to be valid, got %v", pattern, errors)
+		}
+	}
+
+	for _, pattern := range []string{
+		"0 2 * * *",
+		"0 0 2 * * * *",
+		"0 0 2 ? * ? *",
+		"0 60 2 * * ? *",
+		"0 0 24 * * ? *",
+		"0 0 2 * 13 ? *",
+		"0 0 2 * * ? $",
+	} {
+		if _, errors := validatePipelineScheduleCron(pattern, "cron_pattern"); len(errors) == 0 {
+			t.Errorf("expected %s to be invalid", pattern)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package bitbucket
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"golang.org/x/crypto/ssh"
+)
+
+// PipelineSSHKeyPair is the key pair pipelines use to connect to other hosts, bitbucket never returns the private key
+type PipelineSSHKeyPair struct {
+	PrivateKey string `json:"private_key,omitempty"`
+	PublicKey  string `json:"public_key,omitempty"`
+}
+
+func resourcePipelineSSHKey() *schema.Resource {
+	return &schema.Resource{
+		Create: resourcePipelineSSHKeyCreate,
+		Read:   resourcePipelineSSHKeyRead,
+		Update: resourcePipelineSSHKeyUpdate,
+		Delete: resourcePipelineSSHKeyDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourcePipelineSSHKeyImport,
+		},
+
+		CustomizeDiff: resourcePipelineSSHKeyDiff,
+
+		Schema: map[string]*schema.Schema{
+			"workspace": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"repository": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"private_key": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Sensitive:    true,
+				ValidateFunc: validateSSHPrivateKey,
+			},
+			"public_key": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validateSSHPublicKey,
+			},
+			"fingerprint": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// sameSSHPublicKey compares two public keys in authorized_keys format, ignoring their comments
+func sameSSHPublicKey(a, b string) bool {
+	pubA, _, errA := parseSSHPublicKey(a)
+	pubB, _, errB := parseSSHPublicKey(b)
+
+	if errA != nil || errB != nil {
+		return false
+	}
+
+	return formatSSHPublicKey(pubA) == formatSSHPublicKey(pubB)
+}
+
+func resourcePipelineSSHKeyDiff(d *schema.ResourceDiff, m interface{}) error {
+	// A public key on its own would be replaced by a generated pair. The private key of a new resource is computed
+	// when it is left out, which can not be told apart from one that is not known yet, so both are rejected.
+	missingPrivateKey := d.Get("private_key").(string) == ""
+	if !d.NewValueKnown("private_key") {
+		missingPrivateKey = d.Id() == ""
+	}
+
+	if missingPrivateKey && d.NewValueKnown("public_key") && d.Get("public_key").(string) != "" {
+		return fmt.Errorf("public_key can only be set together with private_key")
+	}
+
+	if !d.NewValueKnown("private_key") || !d.NewValueKnown("public_key") {
+		return nil
+	}
+
+	// Without a private key one is generated when the resource is created.
+	privateKey := d.Get("private_key").(string)
+	if privateKey == "" {
+		return nil
+	}
+
+	derived, err := sshPublicKeyFromPrivateKey(privateKey)
+	if err != nil {
+		return err
+	}
+
+	if publicKey := d.Get("public_key").(string); d.HasChange("public_key") && publicKey != "" {
+		if !sameSSHPublicKey(publicKey, derived) {
+			return fmt.Errorf("public_key does not belong to private_key")
+		}
+		return nil
+	}
+
+	if d.HasChange("private_key") {
+		if err := d.SetNew("public_key", derived); err != nil {
+			return err
+		}
+
+		pub, _, _ := parseSSHPublicKey(derived)
+		return d.SetNew("fingerprint", ssh.FingerprintSHA256(pub))
+	}
+
+	return nil
+}
+
+func putPipelineSSHKey(d *schema.ResourceData, client *Client) error {
+	privateKey := d.Get("private_key").(string)
+	publicKey := d.Get("public_key").(string)
+
+	var err error
+	if privateKey == "" {
+		log.Printf("[DEBUG] Generating a pipelines SSH key pair for %s/%s", d.Get("workspace").(string), d.Get("repository").(string))
+		privateKey, publicKey, err = generateSSHKeyPair()
+	} else if publicKey == "" {
+		publicKey, err = sshPublicKeyFromPrivateKey(privateKey)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	bytedata, err := json.Marshal(&PipelineSSHKeyPair{
+		PrivateKey: privateKey,
+		PublicKey:  publicKey,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Put(fmt.Sprintf("2.0/repositories/%s/%s/pipelines_config/ssh/key_pair",
+		d.Get("workspace").(string),
+		d.Get("repository").(string),
+	), bytes.NewBuffer(bytedata))
+
+	if err != nil {
+		return err
+	}
+
+	d.Set("private_key", privateKey)
+	d.Set("public_key", publicKey)
+
+	return nil
+}
+
+func resourcePipelineSSHKeyCreate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	if err := putPipelineSSHKey(d, client); err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", d.Get("workspace").(string), d.Get("repository").(string)))
+
+	return resourcePipelineSSHKeyRead(d, m)
+}
+
+func resourcePipelineSSHKeyRead(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	keyPairReq, err := client.Get(fmt.Sprintf("2.0/repositories/%s/%s/pipelines_config/ssh/key_pair",
+		d.Get("workspace").(string),
+		d.Get("repository").(string),
+	))
+
+	if keyPairReq != nil && keyPairReq.StatusCode == 404 {
+		d.SetId("")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var keyPair PipelineSSHKeyPair
+	body, readerr := ioutil.ReadAll(keyPairReq.Body)
+	if readerr != nil {
+		return readerr
+	}
+
+	decodeerr := json.Unmarshal(body, &keyPair)
+	if decodeerr != nil {
+		return decodeerr
+	}
+
+	pub, _, err := parseSSHPublicKey(keyPair.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	// The private key we have belongs to the old pair when the key pair was replaced in the UI, planning puts
+	// ours back.
+	if publicKey := d.Get("public_key").(string); publicKey != "" && !sameSSHPublicKey(publicKey, keyPair.PublicKey) {
+		log.Printf("[WARN] Pipelines SSH key pair of %s was replaced outside of terraform", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	// Keep the public key as configured, bitbucket may drop its comment.
+	if d.Get("public_key").(string) == "" {
+		d.Set("public_key", keyPair.PublicKey)
+	}
+	d.Set("fingerprint", ssh.FingerprintSHA256(pub))
+
+	return nil
+}
+
+func resourcePipelineSSHKeyUpdate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	if d.HasChange("private_key") || d.HasChange("public_key") {
+		if err := putPipelineSSHKey(d, client); err != nil {
+			return err
+		}
+	}
+
+	return resourcePipelineSSHKeyRead(d, m)
+}
+
+func resourcePipelineSSHKeyDelete(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	keyPairReq, err := client.Delete(fmt.Sprintf("2.0/repositories/%s/%s/pipelines_config/ssh/key_pair",
+		d.Get("workspace").(string),
+		d.Get("repository").(string),
+	))
+
+	if keyPairReq != nil && keyPairReq.StatusCode == 404 {
+		return nil
+	}
+
+	return err
+}
+
+func resourcePipelineSSHKeyImport(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	idparts := strings.Split(d.Id(), "/")
+	if len(idparts) != 2 {
+		return nil, fmt.Errorf("Incorrect ID format, should match `workspace/repository`")
+	}
+
+	d.Set("workspace", idparts[0])
+	d.Set("repository", idparts[1])
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,102 @@
+package bitbucket
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccBitbucketPipelineSSHKey_generated(t *testing.T) {
+	testUser := os.Getenv("BITBUCKET_USERNAME")
+	testAccBitbucketPipelineSSHKeyConfig := fmt.Sprintf(`
+		resource "bitbucket_repository" "test_repo" {
+			owner = "%s"
+			name = "test-repo-for-pipeline-ssh-key-test"
+		}
+
+		resource "bitbucket_pipeline_ssh_key" "test" {
+			workspace = bitbucket_repository.test_repo.owner
+			repository = bitbucket_repository.test_repo.name
+		}
+	`, testUser)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBitbucketPipelineSSHKeyConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("bitbucket_pipeline_ssh_key.test", "private_key"),
+					resource.TestCheckResourceAttrSet("bitbucket_pipeline_ssh_key.test", "public_key"),
+					resource.TestCheckResourceAttrSet("bitbucket_pipeline_ssh_key.test", "fingerprint"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccBitbucketPipelineSSHKey_keyPair(t *testing.T) {
+	privateKey, publicKey, err := generateSSHKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testUser := os.Getenv("BITBUCKET_USERNAME")
+	testAccBitbucketPipelineSSHKeyConfig := fmt.Sprintf(`
+		resource "bitbucket_repository" "test_repo" {
+			owner = "%s"
+			name = "test-repo-for-pipeline-ssh-key-test"
+		}
+
+		resource "bitbucket_pipeline_ssh_key" "test" {
+			workspace = bitbucket_repository.test_repo.owner
+			repository = bitbucket_repository.test_repo.name
+			private_key = %q
+			public_key = %q
+		}
+	`, testUser, privateKey, publicKey)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBitbucketPipelineSSHKeyConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("bitbucket_pipeline_ssh_key.test", "private_key", privateKey),
+					resource.TestCheckResourceAttr("bitbucket_pipeline_ssh_key.test", "public_key", publicKey),
+					resource.TestCheckResourceAttrSet("bitbucket_pipeline_ssh_key.test", "fingerprint"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccBitbucketPipelineSSHKey_publicKeyOnly(t *testing.T) {
+	_, publicKey, err := generateSSHKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					resource "bitbucket_pipeline_ssh_key" "test" {
+						workspace = "myteam"
+						repository = "terraform-code"
+						public_key = %q
+					}
+				`, publicKey),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("public_key can only be set together with private_key"),
+			},
+		},
+	})
+}
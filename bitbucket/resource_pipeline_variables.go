@@ -0,0 +1,352 @@
+package bitbucket
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourcePipelineVariables() *schema.Resource {
+	return &schema.Resource{
+		Create: resourcePipelineVariablesCreate,
+		Read:   resourcePipelineVariablesRead,
+		Update: resourcePipelineVariablesUpdate,
+		Delete: resourcePipelineVariablesDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourcePipelineVariablesImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultVisibilityTimeout),
+			Update: schema.DefaultTimeout(defaultVisibilityTimeout),
+		},
+
+		CustomizeDiff: resourcePipelineVariablesDiff,
+
+		Schema: map[string]*schema.Schema{
+			"workspace": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"repository", "deployment"},
+			},
+			"repository": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"workspace", "deployment"},
+			},
+			"deployment": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"workspace", "repository"},
+			},
+			"delete_unmanaged": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"variable": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validateVariableKey,
+						},
+						"value": {
+							Type:      schema.TypeString,
+							Required:  true,
+							Sensitive: true,
+						},
+						"secured": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// pipelineVariablesScope is whichever of workspace, repository or deployment is set
+func pipelineVariablesScope(d *schema.ResourceData) string {
+	for _, k := range []string{"workspace", "repository", "deployment"} {
+		if scope := d.Get(k).(string); scope != "" {
+			return scope
+		}
+	}
+
+	return ""
+}
+
+func newPipelineVariableFromMap(m map[string]interface{}) *PipelineVariable {
+	return &PipelineVariable{
+		Key:     m["key"].(string),
+		Value:   m["value"].(string),
+		Secured: m["secured"].(bool),
+	}
+}
+
+// pipelineVariableUpToDate tells if the variable on bitbucket already is the wanted one. The value of a secured
+// variable can not be compared, it is up to date when the state says we wrote the same value.
+func pipelineVariableUpToDate(current PipelineVariable, want *PipelineVariable, previous map[string]interface{}) bool {
+	if current.Secured != want.Secured {
+		return false
+	}
+
+	if !want.Secured {
+		return current.Value == want.Value
+	}
+
+	return previous != nil && previous["value"].(string) == want.Value && previous["secured"].(bool)
+}
+
+func resourcePipelineVariablesDiff(d *schema.ResourceDiff, m interface{}) error {
+	if d.NewValueKnown("workspace") && d.NewValueKnown("repository") && d.NewValueKnown("deployment") &&
+		d.Get("workspace").(string) == "" && d.Get("repository").(string) == "" && d.Get("deployment").(string) == "" {
+		return fmt.Errorf("one of workspace, repository or deployment must be set")
+	}
+
+	if !d.NewValueKnown("variable") {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for _, item := range d.Get("variable").(*schema.Set).List() {
+		key := item.(map[string]interface{})["key"].(string)
+		if seen[key] {
+			return fmt.Errorf("variable %s is declared more than once", key)
+		}
+		seen[key] = true
+	}
+
+	return nil
+}
+
+// syncPipelineVariables makes the variables of the scope match the declared ones with one listing and only the
+// creates, updates and deletes that are needed. Variables are matched up by key.
+func syncPipelineVariables(d *schema.ResourceData, client *Client, timeout string) error {
+	scope := pipelineVariablesScope(d)
+
+	existing, err := listPipelineVariables(client, scope)
+	if err != nil {
+		return err
+	}
+
+	existingByKey := make(map[string]PipelineVariable)
+	for _, variable := range existing {
+		existingByKey[variable.Key] = variable
+	}
+
+	o, _ := d.GetChange("variable")
+	previous := make(map[string]map[string]interface{})
+	for _, item := range o.(*schema.Set).List() {
+		variable := item.(map[string]interface{})
+		previous[variable["key"].(string)] = variable
+	}
+
+	declared := make(map[string]bool)
+	var created []string
+
+	for _, item := range d.Get("variable").(*schema.Set).List() {
+		variable := newPipelineVariableFromMap(item.(map[string]interface{}))
+		declared[variable.Key] = true
+
+		current, ok := existingByKey[variable.Key]
+		if !ok {
+			log.Printf("[DEBUG] Creating pipeline variable %s on %s", variable.Key, scope)
+			if _, err := postPipelineVariable(client, scope, variable); err != nil {
+				return err
+			}
+			created = append(created, variable.Key)
+			continue
+		}
+
+		if pipelineVariableUpToDate(current, variable, previous[variable.Key]) {
+			continue
+		}
+
+		log.Printf("[DEBUG] Updating pipeline variable %s on %s", variable.Key, scope)
+		if err := putPipelineVariable(client, scope, current.UUID, variable); err != nil {
+			return err
+		}
+	}
+
+	deleteUnmanaged := d.Get("delete_unmanaged").(bool)
+
+	for key, current := range existingByKey {
+		if declared[key] {
+			continue
+		}
+
+		// Variables we used to manage are always removed, the others only when asked to.
+		if _, managed := previous[key]; !managed && !deleteUnmanaged {
+			continue
+		}
+
+		log.Printf("[DEBUG] Deleting pipeline variable %s on %s", key, scope)
+		if err := deletePipelineVariable(client, scope, current.UUID); err != nil {
+			return err
+		}
+	}
+
+	if len(created) == 0 {
+		return nil
+	}
+
+	// The listing is cached, wait for the new variables so the read afterwards does not lose them.
+	return waitUntilVisible(fmt.Sprintf("pipeline variables on %s", scope), d.Timeout(timeout), func() (bool, error) {
+		variables, err := listPipelineVariables(client, scope)
+		if err != nil {
+			return false, err
+		}
+
+		visible := make(map[string]bool)
+		for _, variable := range variables {
+			visible[variable.Key] = true
+		}
+
+		for _, key := range created {
+			if !visible[key] {
+				return false, nil
+			}
+		}
+
+		return true, nil
+	})
+}
+
+func resourcePipelineVariablesCreate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	if err := syncPipelineVariables(d, client, schema.TimeoutCreate); err != nil {
+		return err
+	}
+
+	d.SetId(pipelineVariablesScope(d))
+
+	return resourcePipelineVariablesRead(d, m)
+}
+
+func resourcePipelineVariablesRead(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	existing, err := listPipelineVariables(client, d.Id())
+	if isNotFound(err) {
+		log.Printf("[WARN] Pipeline variables scope %s not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	declared := make(map[string]map[string]interface{})
+	for _, item := range d.Get("variable").(*schema.Set).List() {
+		variable := item.(map[string]interface{})
+		declared[variable["key"].(string)] = variable
+	}
+
+	deleteUnmanaged := d.Get("delete_unmanaged").(bool)
+	variables := make([]interface{}, 0, len(existing))
+
+	for _, variable := range existing {
+		previous, managed := declared[variable.Key]
+
+		// Unmanaged variables go into state when they are going to be deleted, so the plan shows them being
+		// removed. Otherwise they are left alone.
+		if !managed && !deleteUnmanaged {
+			continue
+		}
+
+		// Bitbucket never returns secured values, keep the one we wrote.
+		value := variable.Value
+		if variable.Secured {
+			value = ""
+			if managed {
+				value = previous["value"].(string)
+			}
+		}
+
+		variables = append(variables, map[string]interface{}{
+			"key":     variable.Key,
+			"value":   value,
+			"secured": variable.Secured,
+		})
+	}
+
+	d.Set("variable", variables)
+
+	return nil
+}
+
+func resourcePipelineVariablesUpdate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	if err := syncPipelineVariables(d, client, schema.TimeoutUpdate); err != nil {
+		return err
+	}
+
+	return resourcePipelineVariablesRead(d, m)
+}
+
+func resourcePipelineVariablesDelete(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+	scope := d.Id()
+
+	declared := make(map[string]bool)
+	for _, item := range d.Get("variable").(*schema.Set).List() {
+		declared[item.(map[string]interface{})["key"].(string)] = true
+	}
+
+	existing, err := listPipelineVariables(client, scope)
+	if isNotFound(err) {
+		// The variables went away with their workspace, repository or environment
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, variable := range existing {
+		if !declared[variable.Key] {
+			continue
+		}
+
+		if err := deletePipelineVariable(client, scope, variable.UUID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resourcePipelineVariablesImport takes the scope as ID, a workspace, `owner/repository` or
+// `owner/repository:{uuid}` for a deployment environment
+func resourcePipelineVariablesImport(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	id := d.Id()
+
+	switch {
+	case strings.Contains(id, ":"):
+		d.Set("deployment", id)
+	case strings.Contains(id, "/"):
+		if len(strings.Split(id, "/")) != 2 {
+			return nil, fmt.Errorf("Incorrect ID format, should match `workspace`, `owner/repository` or `owner/repository:{uuid}`")
+		}
+		d.Set("repository", id)
+	default:
+		d.Set("workspace", id)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
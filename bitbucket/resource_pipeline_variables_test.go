@@ -0,0 +1,114 @@
+package bitbucket
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccBitbucketPipelineVariables_basic(t *testing.T) {
+	testUser := os.Getenv("BITBUCKET_USERNAME")
+	testAccBitbucketPipelineVariablesConfig := func(debug string) string {
+		return fmt.Sprintf(`
+			resource "bitbucket_repository" "test_repo" {
+				owner = "%s"
+				name = "test-repo-for-pipeline-variables-test"
+				pipelines_enabled = true
+			}
+
+			resource "bitbucket_pipeline_variables" "test" {
+				repository = bitbucket_repository.test_repo.id
+
+				variable {
+					key = "DEBUG"
+					value = "%s"
+				}
+
+				variable {
+					key = "TOKEN"
+					value = "hunter2"
+					secured = true
+				}
+			}
+		`, testUser, debug)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBitbucketPipelineVariablesDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBitbucketPipelineVariablesConfig("true"),
+				Check:  resource.TestCheckResourceAttr("bitbucket_pipeline_variables.test", "variable.#", "2"),
+			},
+			{
+				Config: testAccBitbucketPipelineVariablesConfig("false"),
+				Check:  resource.TestCheckResourceAttr("bitbucket_pipeline_variables.test", "variable.#", "2"),
+			},
+		},
+	})
+}
+
+func testAccCheckBitbucketPipelineVariablesDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*Client)
+	rs, ok := s.RootModule().Resources["bitbucket_pipeline_variables.test"]
+	if !ok {
+		return fmt.Errorf("Not found %s", "bitbucket_pipeline_variables.test")
+	}
+
+	variables, err := listPipelineVariables(client, rs.Primary.ID)
+	if err != nil {
+		// The repository is gone together with its variables
+		return nil
+	}
+
+	if len(variables) > 0 {
+		return fmt.Errorf("Pipeline variables still exist on %s", rs.Primary.ID)
+	}
+
+	return nil
+}
+
+func TestPipelineVariablesURL(t *testing.T) {
+	cases := map[string]string{
+		"gob":                    "2.0/workspaces/gob/pipelines-config/variables",
+		"gob/illusions":          "2.0/repositories/gob/illusions/pipelines_config/variables",
+		"gob/illusions:{c0ffee}": "2.0/repositories/gob/illusions/deployments_config/environments/{c0ffee}/variables",
+	}
+
+	for scope, expected := range cases {
+		if actual := pipelineVariablesURL(scope); actual != expected {
+			t.Errorf("expected %s for %s, got %s", expected, scope, actual)
+		}
+	}
+}
+
+func TestPipelineVariableUpToDate(t *testing.T) {
+	want := &PipelineVariable{Key: "TOKEN", Value: "hunter2", Secured: true}
+	previous := map[string]interface{}{"key": "TOKEN", "value": "hunter2", "secured": true}
+
+	if !pipelineVariableUpToDate(PipelineVariable{Key: "TOKEN", Secured: true}, want, previous) {
+		t.Errorf("expected a secured variable we wrote to be up to date")
+	}
+
+	if pipelineVariableUpToDate(PipelineVariable{Key: "TOKEN", Secured: true}, want, nil) {
+		t.Errorf("expected a secured variable we did not write to be updated")
+	}
+
+	if pipelineVariableUpToDate(PipelineVariable{Key: "TOKEN", Value: "hunter2"}, want, previous) {
+		t.Errorf("expected a variable that is no longer secured to be updated")
+	}
+
+	plain := &PipelineVariable{Key: "DEBUG", Value: "true"}
+	if !pipelineVariableUpToDate(PipelineVariable{Key: "DEBUG", Value: "true"}, plain, nil) {
+		t.Errorf("expected an unchanged variable to be up to date")
+	}
+
+	if pipelineVariableUpToDate(PipelineVariable{Key: "DEBUG", Value: "false"}, plain, nil) {
+		t.Errorf("expected a changed variable to be updated")
+	}
+}
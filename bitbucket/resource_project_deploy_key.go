@@ -0,0 +1,155 @@
+package bitbucket
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// resourceProjectDeployKey manages deploy keys that read every repository of a project. Unlike repository deploy keys
+// they can not be updated, changing the label replaces the key.
+func resourceProjectDeployKey() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceProjectDeployKeyCreate,
+		Read:   resourceProjectDeployKeyRead,
+		Delete: resourceProjectDeployKeyDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceProjectDeployKeyImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"workspace": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"project": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"key_id": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"key": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validateSSHPublicKey,
+				StateFunc:    deployKeyStateFunc,
+			},
+			"label": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"comment": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func projectDeployKeysURL(d *schema.ResourceData) string {
+	return fmt.Sprintf("2.0/workspaces/%s/projects/%s/deploy-keys",
+		d.Get("workspace").(string),
+		d.Get("project").(string),
+	)
+}
+
+func resourceProjectDeployKeyCreate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	bytedata, err := json.Marshal(&DeployKey{
+		Key:   d.Get("key").(string),
+		Label: d.Get("label").(string),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	deployKeyReq, err := client.Post(projectDeployKeysURL(d), bytes.NewBuffer(bytedata))
+	if err != nil {
+		return err
+	}
+
+	body, readerr := ioutil.ReadAll(deployKeyReq.Body)
+	if readerr != nil {
+		return readerr
+	}
+
+	deployKey, err := decodeDeployKey(body)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(strconv.Itoa(deployKey.ID))
+
+	return resourceProjectDeployKeyRead(d, m)
+}
+
+func resourceProjectDeployKeyRead(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	deployKeyReq, err := client.Get(fmt.Sprintf("%s/%s", projectDeployKeysURL(d), d.Id()))
+
+	if deployKeyReq != nil && deployKeyReq.StatusCode == 404 {
+		d.SetId("")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	body, readerr := ioutil.ReadAll(deployKeyReq.Body)
+	if readerr != nil {
+		return readerr
+	}
+
+	deployKey, err := decodeDeployKey(body)
+	if err != nil {
+		return err
+	}
+
+	setDeployKey(d, deployKey)
+
+	return nil
+}
+
+func resourceProjectDeployKeyDelete(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	deployKeyReq, err := client.Delete(fmt.Sprintf("%s/%s", projectDeployKeysURL(d), d.Id()))
+
+	if deployKeyReq != nil && deployKeyReq.StatusCode == 404 {
+		return nil
+	}
+
+	return err
+}
+
+func resourceProjectDeployKeyImport(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	idparts := strings.Split(d.Id(), "/")
+	if len(idparts) != 3 {
+		return nil, fmt.Errorf("Incorrect ID format, should match `workspace/project/id`")
+	}
+
+	if _, err := strconv.Atoi(idparts[2]); err != nil {
+		return nil, fmt.Errorf("Incorrect ID format, the id of a deploy key is a number, got %s", idparts[2])
+	}
+
+	d.Set("workspace", idparts[0])
+	d.Set("project", idparts[1])
+	d.SetId(idparts[2])
+
+	return []*schema.ResourceData{d}, nil
+}
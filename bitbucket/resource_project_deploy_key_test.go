@@ -0,0 +1,74 @@
+package bitbucket
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccBitbucketProjectDeployKey_basic(t *testing.T) {
+	testTeam := os.Getenv("BITBUCKET_TEAM")
+	testAccBitbucketProjectDeployKeyConfig := func(label string) string {
+		return fmt.Sprintf(`
+			resource "bitbucket_project" "test_project" {
+				owner = "%s"
+				name = "test-project-for-project-deploy-key-test"
+				key = "TESTDEPLOYKEY"
+			}
+
+			resource "bitbucket_project_deploy_key" "test" {
+				workspace = bitbucket_project.test_project.owner
+				project = bitbucket_project.test_project.key
+				key = "%s ci@example.com"
+				label = "%s"
+			}
+		`, testTeam, testSSHPublicKey, label)
+	}
+
+	var firstID string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBitbucketProjectDeployKeyConfig("ci"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("bitbucket_project_deploy_key.test", "key", normalizeSSHPublicKey(testSSHPublicKey)),
+					resource.TestCheckResourceAttr("bitbucket_project_deploy_key.test", "label", "ci"),
+					func(s *terraform.State) error {
+						firstID = s.RootModule().Resources["bitbucket_project_deploy_key.test"].Primary.ID
+						return nil
+					},
+				),
+			},
+			{
+				Config: testAccBitbucketProjectDeployKeyConfig("ci-renamed"),
+				Check: func(s *terraform.State) error {
+					if s.RootModule().Resources["bitbucket_project_deploy_key.test"].Primary.ID == firstID {
+						return fmt.Errorf("expected changing the label to replace the deploy key")
+					}
+					return nil
+				},
+			},
+			{
+				ResourceName:      "bitbucket_project_deploy_key.test",
+				ImportState:       true,
+				ImportStateIdFunc: testAccBitbucketProjectDeployKeyImportId,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccBitbucketProjectDeployKeyImportId(s *terraform.State) (string, error) {
+	rs, ok := s.RootModule().Resources["bitbucket_project_deploy_key.test"]
+	if !ok {
+		return "", fmt.Errorf("Not found %s", "bitbucket_project_deploy_key.test")
+	}
+
+	return fmt.Sprintf("%s/%s/%s", rs.Primary.Attributes["workspace"], rs.Primary.Attributes["project"], rs.Primary.ID), nil
+}
@@ -5,7 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-
+	"net/url"
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
@@ -43,6 +43,50 @@ type Repository struct {
 	} `json:"links,omitempty"`
 }
 
+// PaginatedRepositories is a paginated list that the bitbucket api returns
+type PaginatedRepositories struct {
+	Values []Repository `json:"values,omitempty"`
+	Page   int          `json:"page,omitempty"`
+	Size   int          `json:"size,omitempty"`
+	Next   string       `json:"next,omitempty"`
+}
+
+// listRepositories fetches every page of repositories of the owner that match the BBQL query, an empty query
+// matches all of them
+func listRepositories(client *Client, owner, query string) ([]Repository, error) {
+	var repositories []Repository
+
+	resourceURL := fmt.Sprintf("2.0/repositories/%s", owner)
+	if query != "" {
+		resourceURL = fmt.Sprintf("%s?q=%s", resourceURL, url.QueryEscape(query))
+	}
+
+	for {
+		repositoriesReq, err := client.Get(resourceURL)
+		if err != nil {
+			return nil, err
+		}
+
+		var repositoriesPage PaginatedRepositories
+		decoder := json.NewDecoder(repositoriesReq.Body)
+		err = decoder.Decode(&repositoriesPage)
+		repositoriesReq.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		repositories = append(repositories, repositoriesPage.Values...)
+
+		if repositoriesPage.Next == "" {
+			break
+		}
+
+		resourceURL = nextPageEndpoint(repositoriesPage.Next)
+	}
+
+	return repositories, nil
+}
+
 func resourceRepository() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceRepositoryCreate,
@@ -93,7 +137,8 @@ func resourceRepository() *schema.Resource {
 			"pipelines_enabled": {
 				Type:     schema.TypeBool,
 				Optional: true,
-				Default:  false,
+				Computed: true,
+				// Only managed when set, so it can be left to a bitbucket_pipeline_config instead.
 			},
 			"fork_policy": {
 				Type:     schema.TypeString,
@@ -168,23 +213,13 @@ func resourceRepositoryUpdate(d *schema.ResourceData, m interface{}) error {
 		return err
 	}
 
-	var pipelinesEnabled bool
-	pipelinesEnabled = d.Get("pipelines_enabled").(bool)
-	pipelinesConfig := &PipelinesEnabled{Enabled: pipelinesEnabled}
-
-	bytedata, err := json.Marshal(pipelinesConfig)
-
-	if err != nil {
-		return err
+	if d.HasChange("pipelines_enabled") {
+		err = putRepositoryPipelinesEnabled(client, d.Get("owner").(string), repoSlug, d.Get("pipelines_enabled").(bool))
+		if err != nil {
+			return err
+		}
 	}
 
-	_, err = client.Put(fmt.Sprintf("2.0/repositories/%s/%s/pipelines_config",
-		d.Get("owner").(string),
-		repoSlug), bytes.NewBuffer(bytedata))
-
-	if err != nil {
-		return err
-	}
 	return resourceRepositoryRead(d, m)
 }
 
@@ -214,25 +249,27 @@ func resourceRepositoryCreate(d *schema.ResourceData, m interface{}) error {
 	}
 	d.SetId(string(fmt.Sprintf("%s/%s", d.Get("owner").(string), repoSlug)))
 
-	var pipelinesEnabled bool
-	pipelinesEnabled = d.Get("pipelines_enabled").(bool)
-	pipelinesConfig := &PipelinesEnabled{Enabled: pipelinesEnabled}
+	if pipelinesEnabled, ok := d.GetOkExists("pipelines_enabled"); ok {
+		err = putRepositoryPipelinesEnabled(client, d.Get("owner").(string), repoSlug, pipelinesEnabled.(bool))
+		if err != nil {
+			return err
+		}
+	}
 
-	bytedata, err = json.Marshal(pipelinesConfig)
+	return resourceRepositoryRead(d, m)
+}
 
+func putRepositoryPipelinesEnabled(client *Client, owner, repoSlug string, enabled bool) error {
+	bytedata, err := json.Marshal(&PipelinesEnabled{Enabled: enabled})
 	if err != nil {
 		return err
 	}
 
 	_, err = client.Put(fmt.Sprintf("2.0/repositories/%s/%s/pipelines_config",
-		d.Get("owner").(string),
+		owner,
 		repoSlug), bytes.NewBuffer(bytedata))
 
-	if err != nil {
-		return err
-	}
-
-	return resourceRepositoryRead(d, m)
+	return err
 }
 func resourceRepositoryRead(d *schema.ResourceData, m interface{}) error {
 	id := d.Id()
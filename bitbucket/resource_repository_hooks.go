@@ -0,0 +1,398 @@
+package bitbucket
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceRepositoryHooks() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceRepositoryHooksCreate,
+		Read:   resourceRepositoryHooksRead,
+		Update: resourceRepositoryHooksUpdate,
+		Delete: resourceRepositoryHooksDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceRepositoryHooksImport,
+		},
+
+		CustomizeDiff: resourceRepositoryHooksDiff,
+
+		Schema: map[string]*schema.Schema{
+			"owner": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"repository": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"delete_unmanaged": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"allowed_url_patterns": {
+				Type:     schema.TypeList,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Optional: true,
+			},
+			"hook": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"url": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"active": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+						"skip_cert_verification": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"events": {
+							Type:     schema.TypeSet,
+							Required: true,
+							Elem: &schema.Schema{
+								Type:         schema.TypeString,
+								ValidateFunc: validateHookEvent("repository"),
+							},
+							Set: schema.HashString,
+						},
+					},
+				},
+			},
+			"unmanaged_hooks": {
+				Type:     schema.TypeList,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Computed: true,
+			},
+		},
+	}
+}
+
+// hookURLAllowed tells if the URL matches one of the patterns, where * matches anything. Without patterns every
+// URL is allowed.
+func hookURLAllowed(hookURL string, patterns []interface{}) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	for _, pattern := range patterns {
+		expr := "^" + strings.Replace(regexp.QuoteMeta(pattern.(string)), `\*`, ".*", -1) + "$"
+		if regexp.MustCompile(expr).MatchString(hookURL) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newHookFromMap(m map[string]interface{}) *Hook {
+	events := make([]string, 0, m["events"].(*schema.Set).Len())
+	for _, item := range m["events"].(*schema.Set).List() {
+		events = append(events, item.(string))
+	}
+
+	return &Hook{
+		URL:                  m["url"].(string),
+		Description:          m["description"].(string),
+		Active:               m["active"].(bool),
+		SkipCertVerification: m["skip_cert_verification"].(bool),
+		Events:               events,
+	}
+}
+
+func flattenHook(hook Hook) map[string]interface{} {
+	events := make([]interface{}, 0, len(hook.Events))
+	for _, event := range hook.Events {
+		events = append(events, event)
+	}
+
+	return map[string]interface{}{
+		"url":                    hook.URL,
+		"description":            hook.Description,
+		"active":                 hook.Active,
+		"skip_cert_verification": hook.SkipCertVerification,
+		"events":                 schema.NewSet(schema.HashString, events),
+	}
+}
+
+func resourceRepositoryHooksDiff(d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("hook") || !d.NewValueKnown("allowed_url_patterns") {
+		return nil
+	}
+
+	patterns := d.Get("allowed_url_patterns").([]interface{})
+	seen := make(map[string]bool)
+
+	for _, item := range d.Get("hook").(*schema.Set).List() {
+		hookURL := item.(map[string]interface{})["url"].(string)
+
+		if !hookURLAllowed(hookURL, patterns) {
+			return fmt.Errorf("hook URL %s does not match any of the allowed_url_patterns", hookURL)
+		}
+
+		if seen[hookURL] {
+			return fmt.Errorf("hook URL %s is declared more than once", hookURL)
+		}
+		seen[hookURL] = true
+	}
+
+	// Hooks that are left alone have to match the patterns as well.
+	if !d.NewValueKnown("delete_unmanaged") || d.Get("delete_unmanaged").(bool) || len(patterns) == 0 {
+		return nil
+	}
+
+	if !d.NewValueKnown("owner") || !d.NewValueKnown("repository") {
+		return nil
+	}
+
+	existing, err := listHooks(m.(*Client), d.Get("owner").(string), d.Get("repository").(string))
+
+	// The repository is created by the same apply, it has no hooks yet.
+	if isNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if disallowed := disallowedUnmanagedHookURLs(existing, seen, patterns); len(disallowed) > 0 {
+		return fmt.Errorf("unmanaged hook URLs %s do not match any of the allowed_url_patterns, set delete_unmanaged to remove them",
+			strings.Join(disallowed, ", "))
+	}
+
+	return nil
+}
+
+// disallowedUnmanagedHookURLs are the URLs of hooks on the repository that are not declared and do not match the
+// patterns
+func disallowedUnmanagedHookURLs(existing []Hook, declared map[string]bool, patterns []interface{}) []string {
+	var disallowed []string
+
+	for _, hook := range existing {
+		if declared[hook.URL] || hookURLAllowed(hook.URL, patterns) {
+			continue
+		}
+
+		disallowed = append(disallowed, hook.URL)
+	}
+
+	return disallowed
+}
+
+// hooksByURL groups the hooks by URL, a URL can have more than one hook on the same repository
+func hooksByURL(hooks []Hook) map[string][]Hook {
+	byURL := make(map[string][]Hook)
+	for _, hook := range hooks {
+		byURL[hook.URL] = append(byURL[hook.URL], hook)
+	}
+
+	return byURL
+}
+
+// syncRepositoryHooks makes the hooks on the repository match the declared ones, hooks are matched up by URL. When
+// a declared URL has more than one hook the first one is updated and the others are deleted.
+func syncRepositoryHooks(d *schema.ResourceData, client *Client) error {
+	owner := d.Get("owner").(string)
+	repository := d.Get("repository").(string)
+
+	existing, err := listHooks(client, owner, repository)
+	if err != nil {
+		return err
+	}
+
+	existingByURL := hooksByURL(existing)
+	var remove []Hook
+
+	for _, item := range d.Get("hook").(*schema.Set).List() {
+		hook := newHookFromMap(item.(map[string]interface{}))
+
+		payload, err := json.Marshal(hook)
+		if err != nil {
+			return err
+		}
+
+		if current, ok := existingByURL[hook.URL]; ok {
+			delete(existingByURL, hook.URL)
+			remove = append(remove, current[1:]...)
+
+			_, err = client.Put(fmt.Sprintf("2.0/repositories/%s/%s/hooks/%s",
+				owner,
+				repository,
+				url.PathEscape(current[0].UUID),
+			), bytes.NewBuffer(payload))
+
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		_, err = client.Post(fmt.Sprintf("2.0/repositories/%s/%s/hooks",
+			owner,
+			repository,
+		), bytes.NewBuffer(payload))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	// Hooks that were declared before are ours to delete, whether or not the others are left alone.
+	previous, _ := d.GetChange("hook")
+	previouslyDeclared := make(map[string]bool)
+	for _, item := range previous.(*schema.Set).List() {
+		previouslyDeclared[item.(map[string]interface{})["url"].(string)] = true
+	}
+
+	for hookURL, hooks := range existingByURL {
+		if d.Get("delete_unmanaged").(bool) || previouslyDeclared[hookURL] {
+			remove = append(remove, hooks...)
+		}
+	}
+
+	for _, hook := range remove {
+		log.Printf("[DEBUG] Deleting hook %s (%s) on %s/%s", hook.URL, hook.UUID, owner, repository)
+
+		_, err := client.Delete(fmt.Sprintf("2.0/repositories/%s/%s/hooks/%s",
+			owner,
+			repository,
+			url.PathEscape(hook.UUID),
+		))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func resourceRepositoryHooksCreate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	if err := syncRepositoryHooks(d, client); err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", d.Get("owner").(string), d.Get("repository").(string)))
+
+	return resourceRepositoryHooksRead(d, m)
+}
+
+func resourceRepositoryHooksRead(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	existing, err := listHooks(client,
+		d.Get("owner").(string),
+		d.Get("repository").(string),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	managed := make(map[string]bool)
+	for _, item := range d.Get("hook").(*schema.Set).List() {
+		managed[item.(map[string]interface{})["url"].(string)] = true
+	}
+
+	deleteUnmanaged := d.Get("delete_unmanaged").(bool)
+	hooks := make([]interface{}, 0, len(existing))
+	unmanaged := make([]string, 0)
+
+	for _, hook := range existing {
+		// Hooks we are going to delete go into state, so the plan shows them being removed. Otherwise they
+		// are only reported.
+		if !deleteUnmanaged && !managed[hook.URL] {
+			log.Printf("[WARN] Hook %s on %s is not managed by terraform", hook.URL, d.Id())
+			unmanaged = append(unmanaged, hook.URL)
+			continue
+		}
+
+		hooks = append(hooks, flattenHook(hook))
+	}
+
+	d.Set("hook", hooks)
+	d.Set("unmanaged_hooks", unmanaged)
+
+	return nil
+}
+
+func resourceRepositoryHooksUpdate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	if err := syncRepositoryHooks(d, client); err != nil {
+		return err
+	}
+
+	return resourceRepositoryHooksRead(d, m)
+}
+
+func resourceRepositoryHooksDelete(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+	owner := d.Get("owner").(string)
+	repository := d.Get("repository").(string)
+
+	declared := make(map[string]bool)
+	for _, item := range d.Get("hook").(*schema.Set).List() {
+		declared[item.(map[string]interface{})["url"].(string)] = true
+	}
+
+	existing, err := listHooks(client, owner, repository)
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range existing {
+		if !declared[hook.URL] {
+			continue
+		}
+
+		_, err := client.Delete(fmt.Sprintf("2.0/repositories/%s/%s/hooks/%s",
+			owner,
+			repository,
+			url.PathEscape(hook.UUID),
+		))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func resourceRepositoryHooksImport(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	idparts := strings.Split(d.Id(), "/")
+	if len(idparts) != 2 {
+		return nil, fmt.Errorf("Incorrect ID format, should match `owner/repository`")
+	}
+
+	d.Set("owner", idparts[0])
+	d.Set("repository", idparts[1])
+	d.Set("delete_unmanaged", false)
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,139 @@
+package bitbucket
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccBitbucketRepositoryHooks_basic(t *testing.T) {
+	testUser := os.Getenv("BITBUCKET_USERNAME")
+	testAccBitbucketRepositoryHooksConfig := func(hook string) string {
+		return fmt.Sprintf(`
+			resource "bitbucket_repository" "test_repo" {
+				owner = "%s"
+				name = "test-repo-for-repository-hooks-test"
+			}
+			resource "bitbucket_repository_hooks" "test_repo" {
+				owner = "%s"
+				repository = "${bitbucket_repository.test_repo.name}"
+				allowed_url_patterns = ["https://httpbin.org/*"]
+				%s
+			}
+		`, testUser, testUser, hook)
+	}
+
+	// Removing the hook block deletes the hook even though unmanaged hooks are left alone.
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBitbucketRepositoryHooksConfig(`
+					hook {
+						url = "https://httpbin.org/anything"
+						description = "Test hook for terraform"
+						events = ["repo:push"]
+					}
+				`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("bitbucket_repository_hooks.test_repo", "hook.#", "1"),
+					resource.TestCheckResourceAttr("bitbucket_repository_hooks.test_repo", "delete_unmanaged", "false"),
+				),
+			},
+			{
+				Config: testAccBitbucketRepositoryHooksConfig(""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("bitbucket_repository_hooks.test_repo", "hook.#", "0"),
+					resource.TestCheckResourceAttr("bitbucket_repository_hooks.test_repo", "unmanaged_hooks.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccBitbucketRepositoryHooks_disallowedURL(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					resource "bitbucket_repository_hooks" "test_repo" {
+						owner = "myteam"
+						repository = "terraform-code"
+						allowed_url_patterns = ["https://*.mycompany.com/*"]
+
+						hook {
+							url = "https://leaky.example.com/build"
+							description = "Not allowed"
+							events = ["repo:push"]
+						}
+					}
+				`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("does not match any of the allowed_url_patterns"),
+			},
+		},
+	})
+}
+
+func TestHookURLAllowed(t *testing.T) {
+	patterns := []interface{}{"https://*.mycompany.com/*", "https://ci.example.com/hook"}
+
+	cases := map[string]bool{
+		"https://deploy.mycompany.com/push":     true,
+		"https://ci.example.com/hook":           true,
+		"https://ci.example.com/hook/other":     false,
+		"https://mycompany.com.evil.org/":       false,
+		"http://deploy.mycompany.com/push":      false,
+		"https://deploy.mycompany.com.evil/x/y": false,
+	}
+
+	for hookURL, allowed := range cases {
+		if hookURLAllowed(hookURL, patterns) != allowed {
+			t.Errorf("expected %s allowed to be %t", hookURL, allowed)
+		}
+	}
+
+	if !hookURLAllowed("https://anything.example.org/", nil) {
+		t.Error("expected every URL to be allowed without patterns")
+	}
+}
+
+func TestDisallowedUnmanagedHookURLs(t *testing.T) {
+	existing := []Hook{
+		{UUID: "{1}", URL: "https://deploy.mycompany.com/push"},
+		{UUID: "{2}", URL: "https://leaky.example.com/build"},
+		{UUID: "{3}", URL: "https://declared.example.com/build"},
+	}
+
+	disallowed := disallowedUnmanagedHookURLs(existing,
+		map[string]bool{"https://declared.example.com/build": true},
+		[]interface{}{"https://*.mycompany.com/*"},
+	)
+
+	if len(disallowed) != 1 || disallowed[0] != "https://leaky.example.com/build" {
+		t.Errorf("expected only the leaky hook to be disallowed, got %v", disallowed)
+	}
+}
+
+func TestHooksByURL(t *testing.T) {
+	byURL := hooksByURL([]Hook{
+		{UUID: "{1}", URL: "https://deploy.mycompany.com/push"},
+		{UUID: "{2}", URL: "https://ci.example.com/hook"},
+		{UUID: "{3}", URL: "https://deploy.mycompany.com/push"},
+	})
+
+	if len(byURL) != 2 {
+		t.Fatalf("expected 2 URLs, got %d: %v", len(byURL), byURL)
+	}
+
+	duplicates := byURL["https://deploy.mycompany.com/push"]
+	if len(duplicates) != 2 || duplicates[0].UUID != "{1}" || duplicates[1].UUID != "{3}" {
+		t.Errorf("expected both hooks on the deploy URL in order, got %v", duplicates)
+	}
+}
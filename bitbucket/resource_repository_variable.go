@@ -1,30 +1,29 @@
 package bitbucket
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"log"
-	"net/url"
+	"strings"
 
+	"github.com/hashicorp/terraform/helper/customdiff"
 	"github.com/hashicorp/terraform/helper/schema"
 )
 
-// RepositoryVariable structure for handling key info
-type RepositoryVariable struct {
-	Key     string `json:"key"`
-	Value   string `json:"value"`
-	UUID    string `json:"uuid,omitempty"`
-	Secured bool   `json:"secured"`
-}
-
 func resourceRepositoryVariable() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceRepositoryVariableCreate,
 		Update: resourceRepositoryVariableUpdate,
 		Read:   resourceRepositoryVariableRead,
 		Delete: resourceRepositoryVariableDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceRepositoryVariableImport,
+		},
+
+		CustomizeDiff: customdiff.All(
+			resourceVariableKeyDiff,
+			resourceVariableValueHashDiff,
+			resourceRepositoryVariableScopeDiff,
+		),
 
 		Schema: map[string]*schema.Schema{
 			"uuid": {
@@ -34,16 +33,32 @@ func resourceRepositoryVariable() *schema.Resource {
 			"key": {
 				Type:     schema.TypeString,
 				Required: true,
+				ForceNew: true,
 			},
 			"value": {
-				Type:     schema.TypeString,
-				Required: true,
+				Type:      schema.TypeString,
+				Required:  true,
+				Sensitive: true,
+			},
+			"value_hash": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"value_hash_salt": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
 			},
 			"secured": {
 				Type:     schema.TypeBool,
 				Optional: true,
 				Default:  false,
 			},
+			"workspace": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
 			"repository": {
 				Type:     schema.TypeString,
 				Required: true,
@@ -52,8 +67,50 @@ func resourceRepositoryVariable() *schema.Resource {
 	}
 }
 
-func newRepositoryVariableFromResource(d *schema.ResourceData) *RepositoryVariable {
-	dk := &RepositoryVariable{
+// repositoryVariableScope is the repository as `owner/slug`. Without a workspace the repository already is in
+// that form, which is how it used to be configured.
+func repositoryVariableScope(workspace, repository string) string {
+	if workspace == "" {
+		return repository
+	}
+
+	return workspace + "/" + repository
+}
+
+func resourceRepositoryVariableScopeDiff(d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("workspace") || !d.NewValueKnown("repository") {
+		return nil
+	}
+
+	if d.Get("workspace").(string) == "" && !strings.Contains(d.Get("repository").(string), "/") {
+		return fmt.Errorf("repository has to be `owner/slug` when workspace is not set")
+	}
+
+	if d.Get("workspace").(string) != "" && strings.Contains(d.Get("repository").(string), "/") {
+		return fmt.Errorf("repository has to be the slug without the owner when workspace is set")
+	}
+
+	if d.Id() == "" {
+		return nil
+	}
+
+	// Going from `owner/slug` to a separate workspace is not a move, anything else is.
+	oldWorkspace, newWorkspace := d.GetChange("workspace")
+	oldRepository, newRepository := d.GetChange("repository")
+
+	if repositoryVariableScope(oldWorkspace.(string), oldRepository.(string)) == repositoryVariableScope(newWorkspace.(string), newRepository.(string)) {
+		return nil
+	}
+
+	if d.HasChange("workspace") {
+		return d.ForceNew("workspace")
+	}
+
+	return d.ForceNew("repository")
+}
+
+func newRepositoryVariableFromResource(d *schema.ResourceData) *PipelineVariable {
+	dk := &PipelineVariable{
 		Key:     d.Get("key").(string),
 		Value:   d.Get("value").(string),
 		Secured: d.Get("secured").(bool),
@@ -65,32 +122,19 @@ func resourceRepositoryVariableCreate(d *schema.ResourceData, m interface{}) err
 
 	client := m.(*Client)
 	rvcr := newRepositoryVariableFromResource(d)
-	bytedata, err := json.Marshal(rvcr)
-
-	if err != nil {
-		return err
-	}
-	req, err := client.Post(fmt.Sprintf("2.0/repositories/%s/pipelines_config/variables/",
-		d.Get("repository").(string),
-	), bytes.NewBuffer(bytedata))
+	scope := repositoryVariableScope(d.Get("workspace").(string), d.Get("repository").(string))
 
+	rv, err := postPipelineVariable(client, scope, rvcr)
 	if err != nil {
 		return err
 	}
 
-	var rv RepositoryVariable
-
-	body, readerr := ioutil.ReadAll(req.Body)
-	if readerr != nil {
-		return readerr
-	}
+	d.Set("uuid", rv.UUID)
+	d.SetId(fmt.Sprintf("%s/%s", scope, rv.Key))
 
-	decodeerr := json.Unmarshal(body, &rv)
-	if decodeerr != nil {
-		return decodeerr
+	if err := setVariableValueHash(d, rvcr.Value); err != nil {
+		return err
 	}
-	d.Set("uuid", rv.UUID)
-	d.SetId(rv.Key)
 
 	return resourceRepositoryVariableRead(d, m)
 }
@@ -98,58 +142,42 @@ func resourceRepositoryVariableCreate(d *schema.ResourceData, m interface{}) err
 func resourceRepositoryVariableRead(d *schema.ResourceData, m interface{}) error {
 
 	client := m.(*Client)
-	rvReq, _ := client.Get(fmt.Sprintf("2.0/repositories/%s/pipelines_config/variables/%s",
-		d.Get("repository").(string),
-		d.Get("uuid").(string),
-	))
-
-	log.Printf("ID: %s", url.PathEscape(d.Id()))
-
-	if rvReq.StatusCode == 200 {
-		var rv RepositoryVariable
-		body, readerr := ioutil.ReadAll(rvReq.Body)
-		if readerr != nil {
-			return readerr
-		}
-
-		decodeerr := json.Unmarshal(body, &rv)
-		if decodeerr != nil {
-			return decodeerr
-		}
+	scope := repositoryVariableScope(d.Get("workspace").(string), d.Get("repository").(string))
 
-		d.Set("uuid", rv.UUID)
-		d.Set("key", rv.Key)
-		d.Set("value", rv.Value)
-		d.Set("secured", rv.Secured)
+	rv, err := readPipelineVariable(d, client, scope, getPipelineVariable)
+	if err != nil {
+		return err
 	}
 
-	if rvReq.StatusCode == 404 {
+	if rv == nil {
+		log.Printf("[WARN] Repository variable %s not found, removing from state", d.Id())
 		d.SetId("")
 		return nil
 	}
 
-	return nil
+	d.SetId(fmt.Sprintf("%s/%s", scope, rv.Key))
+	d.Set("uuid", rv.UUID)
+	d.Set("key", rv.Key)
+
+	return setVariableValue(d, rv.Secured, rv.Value)
 }
 
 func resourceRepositoryVariableUpdate(d *schema.ResourceData, m interface{}) error {
 	client := m.(*Client)
 	rvcr := newRepositoryVariableFromResource(d)
-	bytedata, err := json.Marshal(rvcr)
 
-	if err != nil {
-		return err
-	}
-	req, err := client.Put(fmt.Sprintf("2.0/repositories/%s/pipelines_config/variables/%s",
-		d.Get("repository").(string),
+	err := putPipelineVariable(client,
+		repositoryVariableScope(d.Get("workspace").(string), d.Get("repository").(string)),
 		d.Get("uuid").(string),
-	), bytes.NewBuffer(bytedata))
+		rvcr,
+	)
 
 	if err != nil {
 		return err
 	}
 
-	if req.StatusCode != 200 {
-		return nil
+	if err := setVariableValueHash(d, rvcr.Value); err != nil {
+		return err
 	}
 
 	return resourceRepositoryVariableRead(d, m)
@@ -157,9 +185,22 @@ func resourceRepositoryVariableUpdate(d *schema.ResourceData, m interface{}) err
 
 func resourceRepositoryVariableDelete(d *schema.ResourceData, m interface{}) error {
 	client := m.(*Client)
-	_, err := client.Delete(fmt.Sprintf(fmt.Sprintf("2.0/repositories/%s/pipelines_config/variables/%s",
-		d.Get("repository").(string),
+
+	return deletePipelineVariable(client,
+		repositoryVariableScope(d.Get("workspace").(string), d.Get("repository").(string)),
 		d.Get("uuid").(string),
-	)))
-	return err
+	)
+}
+
+func resourceRepositoryVariableImport(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	idparts := strings.Split(d.Id(), "/")
+	if len(idparts) != 3 || idparts[0] == "" || idparts[1] == "" || idparts[2] == "" {
+		return nil, fmt.Errorf("Incorrect ID format, should match `workspace/repository/key`")
+	}
+
+	d.Set("workspace", idparts[0])
+	d.Set("repository", idparts[1])
+	d.Set("key", idparts[2])
+
+	return []*schema.ResourceData{d}, nil
 }
@@ -3,6 +3,7 @@ package bitbucket
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
@@ -41,6 +42,139 @@ func TestAccBitbucketRepositoryVariable_basic(t *testing.T) {
 	})
 }
 
+func TestAccBitbucketRepositoryVariable_secured(t *testing.T) {
+	testUser := os.Getenv("BITBUCKET_USERNAME")
+	testAccBitbucketRepositoryVariableConfig := func(value string) string {
+		return fmt.Sprintf(`
+			resource "bitbucket_repository" "test_repo" {
+				owner = "%s"
+				name = "test-repo-default-reviewers"
+			}
+
+			resource "bitbucket_repository_variable" "testvar" {
+				key = "test"
+				value = "%s"
+				repository = "${bitbucket_repository.test_repo.id}"
+				secured = true
+			}
+		`, testUser, value)
+	}
+
+	// The second apply only passes when refreshing keeps the secured value instead of emptying it.
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBitbucketRepositoryVariableDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBitbucketRepositoryVariableConfig("first"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBitbucketRepositoryVariableExists("bitbucket_repository_variable.testvar", "test", "first"),
+					testAccCheckVariableValueHash("bitbucket_repository_variable.testvar", "first"),
+				),
+			},
+			{
+				Config: testAccBitbucketRepositoryVariableConfig("rotated"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBitbucketRepositoryVariableExists("bitbucket_repository_variable.testvar", "test", "rotated"),
+					testAccCheckVariableValueHash("bitbucket_repository_variable.testvar", "rotated"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccBitbucketRepositoryVariable_workspace(t *testing.T) {
+	testUser := os.Getenv("BITBUCKET_USERNAME")
+	testAccBitbucketRepositoryVariableConfig := fmt.Sprintf(`
+		resource "bitbucket_repository" "test_repo" {
+			owner = "%s"
+			name = "test-repo-default-reviewers"
+		}
+
+		resource "bitbucket_repository_variable" "testvar" {
+			key = "test"
+			value = "test"
+			workspace = bitbucket_repository.test_repo.owner
+			repository = bitbucket_repository.test_repo.name
+		}
+	`, testUser)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBitbucketRepositoryVariableDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBitbucketRepositoryVariableConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBitbucketRepositoryVariableExists("bitbucket_repository_variable.testvar", "test", "test"),
+					resource.TestCheckResourceAttr("bitbucket_repository_variable.testvar", "id", fmt.Sprintf("%s/test-repo-default-reviewers/test", testUser)),
+				),
+			},
+			{
+				ResourceName:      "bitbucket_repository_variable.testvar",
+				ImportState:       true,
+				ImportStateVerify: true,
+				// An imported variable gets a salt of its own.
+				ImportStateVerifyIgnore: []string{"value_hash", "value_hash_salt"},
+			},
+		},
+	})
+}
+
+func TestAccBitbucketRepositoryVariable_workspaceWithOwner(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					resource "bitbucket_repository_variable" "testvar" {
+						key = "test"
+						value = "test"
+						workspace = "myteam"
+						repository = "myteam/terraform-code"
+					}
+				`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("repository has to be the slug without the owner when workspace is set"),
+			},
+		},
+	})
+}
+
+func TestAccBitbucketRepositoryVariable_reservedKey(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					resource "bitbucket_repository_variable" "testvar" {
+						key = "BITBUCKET_BRANCH"
+						value = "test"
+						workspace = "myteam"
+						repository = "terraform-code"
+					}
+				`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("reserved for variables set by bitbucket"),
+			},
+		},
+	})
+}
+
+func TestRepositoryVariableScope(t *testing.T) {
+	if scope := repositoryVariableScope("", "gob/illusions"); scope != "gob/illusions" {
+		t.Errorf("expected the repository to be used as is, got %s", scope)
+	}
+
+	if scope := repositoryVariableScope("gob", "illusions"); scope != "gob/illusions" {
+		t.Errorf("expected the workspace and repository to be joined, got %s", scope)
+	}
+}
+
 func testAccCheckBitbucketRepositoryVariableDestroy(s *terraform.State) error {
 	_, ok := s.RootModule().Resources["bitbucket_repository_variable.testvar"]
 	if !ok {
@@ -68,3 +202,24 @@ func testAccCheckBitbucketRepositoryVariableExists(n, key, value string) resourc
 		return nil
 	}
 }
+
+// testAccCheckVariableValueHash checks the value hash against the salt in state
+func testAccCheckVariableValueHash(n, value string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found %s", n)
+		}
+
+		salt := rs.Primary.Attributes["value_hash_salt"]
+		if salt == "" {
+			return fmt.Errorf("No value hash salt is set")
+		}
+
+		if rs.Primary.Attributes["value_hash"] != hashVariableValue(salt, value) {
+			return fmt.Errorf("The value hash of %s does not match %q", n, value)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,132 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceWorkspaceHook() *schema.Resource {
+	resource := &schema.Resource{
+		Create: resourceWorkspaceHookCreate,
+		Read:   resourceWorkspaceHookRead,
+		Update: resourceWorkspaceHookUpdate,
+		Delete: resourceWorkspaceHookDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceWorkspaceHookImport,
+		},
+
+		CustomizeDiff: resourceHookSecretDiff,
+
+		Schema: map[string]*schema.Schema{
+			"workspace": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+		},
+	}
+
+	for k, v := range hookSchema("workspace") {
+		resource.Schema[k] = v
+	}
+
+	return resource
+}
+
+func resourceWorkspaceHookCreate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	err := saveHook(d, client, fmt.Sprintf("2.0/workspaces/%s/hooks",
+		d.Get("workspace").(string),
+	))
+
+	if err != nil {
+		return err
+	}
+
+	return resourceWorkspaceHookRead(d, m)
+}
+
+func resourceWorkspaceHookRead(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	log.Printf("ID: %s", url.PathEscape(d.Id()))
+
+	hookReq, err := client.Get(fmt.Sprintf("2.0/workspaces/%s/hooks/%s",
+		d.Get("workspace").(string),
+		url.PathEscape(d.Id()),
+	))
+
+	// Removed in the UI, planning recreates it.
+	if hookReq != nil && hookReq.StatusCode == 404 {
+		d.SetId("")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var hook Hook
+
+	body, readerr := ioutil.ReadAll(hookReq.Body)
+	if readerr != nil {
+		return readerr
+	}
+
+	decodeerr := json.Unmarshal(body, &hook)
+	if decodeerr != nil {
+		return decodeerr
+	}
+
+	setHookFields(d, &hook)
+
+	return nil
+}
+
+func resourceWorkspaceHookUpdate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	err := saveHook(d, client, fmt.Sprintf("2.0/workspaces/%s/hooks/%s",
+		d.Get("workspace").(string),
+		url.PathEscape(d.Id()),
+	))
+
+	if err != nil {
+		return err
+	}
+
+	return resourceWorkspaceHookRead(d, m)
+}
+
+func resourceWorkspaceHookDelete(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+	hookReq, err := client.Delete(fmt.Sprintf("2.0/workspaces/%s/hooks/%s",
+		d.Get("workspace").(string),
+		url.PathEscape(d.Id()),
+	))
+
+	if hookReq != nil && hookReq.StatusCode == 404 {
+		return nil
+	}
+
+	return err
+}
+
+func resourceWorkspaceHookImport(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	idparts := strings.SplitN(d.Id(), "/", 2)
+	if len(idparts) != 2 {
+		return nil, fmt.Errorf("Incorrect ID format, should match `workspace/uuid`")
+	}
+
+	d.Set("workspace", idparts[0])
+	d.SetId(idparts[1])
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,68 @@
+package bitbucket
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccBitbucketWorkspaceHook_basic(t *testing.T) {
+	testTeam := os.Getenv("BITBUCKET_TEAM")
+	testAccBitbucketWorkspaceHookConfig := fmt.Sprintf(`
+		resource "bitbucket_workspace_hook" "test_workspace_hook" {
+			workspace = "%s"
+			description = "Test workspace hook for terraform"
+			url = "https://httpbin.org"
+			secret = "not-so-secret"
+			events = [
+				"repo:push",
+			]
+		}
+	`, testTeam)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBitbucketWorkspaceHookDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBitbucketWorkspaceHookConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("bitbucket_workspace_hook.test_workspace_hook", "uuid"),
+					resource.TestCheckResourceAttr("bitbucket_workspace_hook.test_workspace_hook", "secret_hash", hashHookSecret("not-so-secret")),
+				),
+			},
+			{
+				ResourceName:            "bitbucket_workspace_hook.test_workspace_hook",
+				ImportState:             true,
+				ImportStateIdPrefix:     testTeam + "/",
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"secret", "secret_hash"},
+			},
+		},
+	})
+}
+
+func testAccCheckBitbucketWorkspaceHookDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*Client)
+	rs, ok := s.RootModule().Resources["bitbucket_workspace_hook.test_workspace_hook"]
+	if !ok {
+		return fmt.Errorf("Not found %s", "bitbucket_workspace_hook.test_workspace_hook")
+	}
+
+	response, err := client.Get(fmt.Sprintf("2.0/workspaces/%s/hooks/%s", rs.Primary.Attributes["workspace"], url.PathEscape(rs.Primary.ID)))
+
+	if err == nil {
+		return fmt.Errorf("The resource was found should have errored")
+	}
+
+	if response.StatusCode != 404 {
+		return fmt.Errorf("Workspace hook still exists")
+	}
+
+	return nil
+}
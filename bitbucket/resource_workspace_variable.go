@@ -0,0 +1,169 @@
+package bitbucket
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceWorkspaceVariable() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceWorkspaceVariableCreate,
+		Read:   resourceWorkspaceVariableRead,
+		Update: resourceWorkspaceVariableUpdate,
+		Delete: resourceWorkspaceVariableDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceWorkspaceVariableImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultVisibilityTimeout),
+		},
+
+		CustomizeDiff: resourceVariableValueHashDiff,
+
+		Schema: map[string]*schema.Schema{
+			"workspace": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"uuid": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"key": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateVariableKey,
+			},
+			"value": {
+				Type:      schema.TypeString,
+				Required:  true,
+				Sensitive: true,
+			},
+			"value_hash": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"value_hash_salt": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"secured": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+		},
+	}
+}
+
+func newWorkspaceVariableFromResource(d *schema.ResourceData) *PipelineVariable {
+	return &PipelineVariable{
+		Key:     d.Get("key").(string),
+		Value:   d.Get("value").(string),
+		Secured: d.Get("secured").(bool),
+	}
+}
+
+func resourceWorkspaceVariableCreate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+	workspace := d.Get("workspace").(string)
+	variable := newWorkspaceVariableFromResource(d)
+
+	created, err := postPipelineVariable(client, workspace, variable)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(created.UUID)
+	d.Set("uuid", created.UUID)
+
+	if err := setVariableValueHash(d, variable.Value); err != nil {
+		return err
+	}
+
+	err = waitUntilVisible(fmt.Sprintf("workspace variable %s", variable.Key), d.Timeout(schema.TimeoutCreate), func() (bool, error) {
+		found, err := getPipelineVariable(client, workspace, created.UUID)
+		return found != nil, err
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return resourceWorkspaceVariableRead(d, m)
+}
+
+func resourceWorkspaceVariableRead(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	variable, err := getPipelineVariable(client, d.Get("workspace").(string), d.Id())
+	if err != nil {
+		return err
+	}
+
+	if variable == nil {
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("uuid", variable.UUID)
+	d.Set("key", variable.Key)
+
+	return setVariableValue(d, variable.Secured, variable.Value)
+}
+
+func resourceWorkspaceVariableUpdate(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+	variable := newWorkspaceVariableFromResource(d)
+
+	if err := putPipelineVariable(client, d.Get("workspace").(string), d.Id(), variable); err != nil {
+		return err
+	}
+
+	if err := setVariableValueHash(d, variable.Value); err != nil {
+		return err
+	}
+
+	return resourceWorkspaceVariableRead(d, m)
+}
+
+func resourceWorkspaceVariableDelete(d *schema.ResourceData, m interface{}) error {
+	client := m.(*Client)
+
+	return deletePipelineVariable(client, d.Get("workspace").(string), d.Id())
+}
+
+// resourceWorkspaceVariableImport takes `workspace/{uuid}` or `workspace/KEY`, a key is looked up in the variables
+// of the workspace
+func resourceWorkspaceVariableImport(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	idparts := strings.Split(d.Id(), "/")
+	if len(idparts) != 2 || idparts[0] == "" || idparts[1] == "" {
+		return nil, fmt.Errorf("Incorrect ID format, should match `workspace/{uuid}` or `workspace/key`")
+	}
+
+	workspace, uuid := idparts[0], idparts[1]
+
+	if !strings.HasPrefix(uuid, "{") {
+		variable, err := findPipelineVariableByKey(m.(*Client), workspace, idparts[1])
+		if err != nil {
+			return nil, err
+		}
+
+		if variable == nil {
+			return nil, fmt.Errorf("no variable named %s in workspace %s", idparts[1], workspace)
+		}
+
+		uuid = variable.UUID
+	}
+
+	d.Set("workspace", workspace)
+	d.SetId(uuid)
+
+	return []*schema.ResourceData{d}, nil
+}
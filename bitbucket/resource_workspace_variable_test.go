@@ -0,0 +1,72 @@
+package bitbucket
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccBitbucketWorkspaceVariable_basic(t *testing.T) {
+	testTeam := os.Getenv("BITBUCKET_TEAM")
+	testAccBitbucketWorkspaceVariableConfig := func(value string, secured bool) string {
+		return fmt.Sprintf(`
+			resource "bitbucket_workspace_variable" "test" {
+				workspace = "%s"
+				key = "TF_ACC_REGISTRY_TOKEN"
+				value = "%s"
+				secured = %t
+			}
+		`, testTeam, value, secured)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBitbucketWorkspaceVariableDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBitbucketWorkspaceVariableConfig("first", false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("bitbucket_workspace_variable.test", "value", "first"),
+					resource.TestCheckResourceAttrSet("bitbucket_workspace_variable.test", "uuid"),
+				),
+			},
+			{
+				Config: testAccBitbucketWorkspaceVariableConfig("rotated", true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("bitbucket_workspace_variable.test", "value", "rotated"),
+					testAccCheckVariableValueHash("bitbucket_workspace_variable.test", "rotated"),
+				),
+			},
+			{
+				ResourceName:            "bitbucket_workspace_variable.test",
+				ImportState:             true,
+				ImportStateId:           fmt.Sprintf("%s/TF_ACC_REGISTRY_TOKEN", testTeam),
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"value", "value_hash", "value_hash_salt"},
+			},
+		},
+	})
+}
+
+func testAccCheckBitbucketWorkspaceVariableDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*Client)
+	rs, ok := s.RootModule().Resources["bitbucket_workspace_variable.test"]
+	if !ok {
+		return fmt.Errorf("Not found %s", "bitbucket_workspace_variable.test")
+	}
+
+	variable, err := getPipelineVariable(client, rs.Primary.Attributes["workspace"], rs.Primary.ID)
+	if err != nil {
+		return err
+	}
+
+	if variable != nil {
+		return fmt.Errorf("Workspace variable still exists")
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package bitbucket
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// generatedSSHKeyBits is the size of the RSA keys we generate
+const generatedSSHKeyBits = 4096
+
+// generateSSHKeyPair creates an RSA key pair, the private key PEM encoded and the public key in authorized_keys
+// format
+func generateSSHKeyPair() (privateKey string, publicKey string, err error) {
+	key, err := rsa.GenerateKey(rand.Reader, generatedSSHKeyBits)
+	if err != nil {
+		return "", "", err
+	}
+
+	pub, err := ssh.NewPublicKey(&key.PublicKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	privateKey = string(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}))
+
+	return privateKey, formatSSHPublicKey(pub), nil
+}
+
+// formatSSHPublicKey is the public key in authorized_keys format without a comment or trailing newline
+func formatSSHPublicKey(pub ssh.PublicKey) string {
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(pub)))
+}
+
+// parseSSHPublicKey parses a public key in authorized_keys format
+func parseSSHPublicKey(publicKey string) (ssh.PublicKey, string, error) {
+	pub, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+	if err != nil {
+		return nil, "", fmt.Errorf("not a valid SSH public key: %s", err)
+	}
+
+	return pub, comment, nil
+}
+
+// sshPublicKeyFromPrivateKey derives the public key in authorized_keys format from a PEM encoded private key
+func sshPublicKeyFromPrivateKey(privateKey string) (string, error) {
+	signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+	if err != nil {
+		return "", fmt.Errorf("not a valid unencrypted SSH private key: %s", err)
+	}
+
+	return formatSSHPublicKey(signer.PublicKey()), nil
+}
+
+func validateSSHPublicKey(v interface{}, k string) (ws []string, errors []error) {
+	if _, _, err := parseSSHPublicKey(v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%s is %s", k, err))
+	}
+	return
+}
+
+func validateSSHPrivateKey(v interface{}, k string) (ws []string, errors []error) {
+	if _, err := sshPublicKeyFromPrivateKey(v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%s is %s", k, err))
+	}
+	return
+}
+
+// normalizeSSHPublicKey drops the comment and extra whitespace from a public key, keys that do not parse are only
+// trimmed and left for validation to reject
+func normalizeSSHPublicKey(publicKey string) string {
+	pub, _, err := parseSSHPublicKey(publicKey)
+	if err != nil {
+		return strings.TrimSpace(publicKey)
+	}
+
+	return formatSSHPublicKey(pub)
+}
@@ -0,0 +1,77 @@
+package bitbucket
+
+import (
+	"testing"
+)
+
+const testSSHPublicKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIPKQe85z3gdtAdjQYjrGTcALQqf8pFBt/Sp68ZFBuXyq host"
+
+func TestGenerateSSHKeyPair(t *testing.T) {
+	privateKey, publicKey, err := generateSSHKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	derived, err := sshPublicKeyFromPrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("expected the generated private key to parse, got %s", err)
+	}
+
+	if derived != publicKey {
+		t.Errorf("expected the public key %s to belong to the private key, got %s", publicKey, derived)
+	}
+
+	if !sameSSHPublicKey(publicKey+" with a comment", publicKey) {
+		t.Errorf("expected comments to be ignored when comparing public keys")
+	}
+
+	if sameSSHPublicKey(publicKey, testSSHPublicKey) {
+		t.Errorf("expected different public keys not to match")
+	}
+}
+
+func TestValidateSSHKeys(t *testing.T) {
+	if _, errors := validateSSHPublicKey(testSSHPublicKey, "public_key"); len(errors) > 0 {
+		t.Errorf("expected a valid public key, got %v", errors)
+	}
+
+	if _, errors := validateSSHPublicKey("ssh-rsa not-base64", "public_key"); len(errors) == 0 {
+		t.Errorf("expected an invalid public key to be rejected")
+	}
+
+	if _, errors := validateSSHPrivateKey(testSSHPublicKey, "private_key"); len(errors) == 0 {
+		t.Errorf("expected a public key to be rejected as private key")
+	}
+}
+
+func TestNewPipelineKnownHostPublicKey(t *testing.T) {
+	publicKey, err := newPipelineKnownHostPublicKey(testSSHPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := PipelineKnownHostPublicKey{
+		KeyType:           "ssh-ed25519",
+		Key:               "AAAAC3NzaC1lZDI1NTE5AAAAIPKQe85z3gdtAdjQYjrGTcALQqf8pFBt/Sp68ZFBuXyq",
+		MD5Fingerprint:    "00:9c:aa:08:71:c2:5f:c9:6d:a3:4d:14:0d:8a:4b:53",
+		SHA256Fingerprint: "SHA256:hv6zCeEbuB2Gcjm3z0CyZn8P7uW09SNBJKGLX3d3Hr0",
+	}
+
+	if *publicKey != expected {
+		t.Errorf("expected %+v, got %+v", expected, *publicKey)
+	}
+}
+
+func TestNormalizeSSHPublicKey(t *testing.T) {
+	expected := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIPKQe85z3gdtAdjQYjrGTcALQqf8pFBt/Sp68ZFBuXyq"
+
+	for _, publicKey := range []string{
+		testSSHPublicKey,
+		expected,
+		"  ssh-ed25519   AAAAC3NzaC1lZDI1NTE5AAAAIPKQe85z3gdtAdjQYjrGTcALQqf8pFBt/Sp68ZFBuXyq  ci@example.com\n",
+	} {
+		if actual := normalizeSSHPublicKey(publicKey); actual != expected {
+			t.Errorf("expected %q to normalize to %q, got %q", publicKey, expected, actual)
+		}
+	}
+}
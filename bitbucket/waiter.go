@@ -0,0 +1,42 @@
+package bitbucket
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+// defaultVisibilityTimeout is how long we wait by default for a created object to show up in reads
+const defaultVisibilityTimeout = 2 * time.Minute
+
+// waitUntilVisible polls exists until it finds the object that was just written. Bitbucket serves some reads from
+// a cache that lags behind writes, reading straight after a create can come back without the new object. The
+// polls back off from 100ms up to 10s between attempts and give up after the timeout.
+func waitUntilVisible(description string, timeout time.Duration, exists func() (bool, error)) error {
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{"pending"},
+		Target:  []string{"visible"},
+		Refresh: func() (interface{}, string, error) {
+			found, err := exists()
+			if err != nil {
+				return nil, "", err
+			}
+
+			if !found {
+				log.Printf("[DEBUG] Waiting for %s to become visible", description)
+				return false, "pending", nil
+			}
+
+			return true, "visible", nil
+		},
+		Timeout: timeout,
+	}
+
+	if _, err := stateConf.WaitForState(); err != nil {
+		return fmt.Errorf("waiting for %s to become visible: %s", description, err)
+	}
+
+	return nil
+}
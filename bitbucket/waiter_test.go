@@ -0,0 +1,44 @@
+package bitbucket
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWaitUntilVisible(t *testing.T) {
+	polls := 0
+	err := waitUntilVisible("test object", time.Minute, func() (bool, error) {
+		polls++
+		return polls == 3, nil
+	})
+
+	if err != nil {
+		t.Fatalf("expected the object to become visible, got %s", err)
+	}
+
+	if polls != 3 {
+		t.Errorf("expected 3 polls, got %d", polls)
+	}
+}
+
+func TestWaitUntilVisibleError(t *testing.T) {
+	err := waitUntilVisible("test object", time.Minute, func() (bool, error) {
+		return false, fmt.Errorf("boom")
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("expected the polling error to be returned, got %v", err)
+	}
+}
+
+func TestWaitUntilVisibleTimeout(t *testing.T) {
+	err := waitUntilVisible("test object", 500*time.Millisecond, func() (bool, error) {
+		return false, nil
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "test object") {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+}
@@ -1,5 +1,8 @@
 module github.com/terraform-providers/terraform-provider-bitbucket
 
-require github.com/hashicorp/terraform v0.12.2
+require (
+	github.com/hashicorp/terraform v0.12.2
+	golang.org/x/crypto v0.0.0-20190426145343-a29dc8fdc734
+)
 
 go 1.13
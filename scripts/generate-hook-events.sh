@@ -0,0 +1,28 @@
+#!/usr/bin/env bash
+
+# Refresh the built in catalog of hook events that bitbucket_hook validates against
+set -euo pipefail
+
+catalog="$(cd "$(dirname "$0")/.." && pwd)/bitbucket/hook_events_catalog.go"
+
+echo "==> Fetching hook events from the Bitbucket API..."
+{
+    echo "// Code generated by scripts/generate-hook-events.sh; DO NOT EDIT."
+    echo ""
+    echo "package bitbucket"
+    echo ""
+    echo "// hookEventsCatalog is every event a hook can subscribe to, keyed by the subject type of the hook"
+    echo "var hookEventsCatalog = map[string][]string{"
+    for subject in repository workspace; do
+        echo "\"${subject}\": {"
+        curl -sSf "https://api.bitbucket.org/2.0/hook_events/${subject}?pagelen=100" \
+            | jq -r '.values[].event' \
+            | sort \
+            | sed 's/.*/"&",/'
+        echo "},"
+    done
+    echo "}"
+} > "${catalog}.tmp"
+
+gofmt "${catalog}.tmp" > "${catalog}"
+rm "${catalog}.tmp"